@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"strings"
+
+	"github.com/aditisaxena259/mental-health-be/config"
+	"github.com/aditisaxena259/mental-health-be/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// canAccessStudentBlock reports whether the requester may act on records belonging to a
+// student of studentBlock. Chief admins see every block; block admins only their own.
+func canAccessStudentBlock(c *fiber.Ctx, studentBlock string) bool {
+	role, _ := c.Locals("role").(string)
+	if role == string(models.ChiefAdmin) {
+		return true
+	}
+	if role != string(models.Admin) {
+		return false
+	}
+	requesterID, _ := c.Locals("user_id").(string)
+	var adminUser models.User
+	if requesterID == "" || config.DB.First(&adminUser, "id = ?", requesterID).Error != nil {
+		return false
+	}
+	adminBlock := strings.TrimSpace(adminUser.Block)
+	return adminBlock != "" && adminBlock == strings.TrimSpace(studentBlock)
+}
+
+// blockAdminsFor returns the block wardens of the given block plus every chief admin,
+// deduplicated by ID. Used to fan out admin notifications.
+func blockAdminsFor(block string) []models.User {
+	var admins []models.User
+	if block != "" {
+		config.DB.Where("role = ? AND block = ?", models.Admin, block).Find(&admins)
+	}
+	var chiefs []models.User
+	config.DB.Where("role = ?", models.ChiefAdmin).Find(&chiefs)
+	seen := map[string]bool{}
+	out := []models.User{}
+	for _, u := range append(admins, chiefs...) {
+		if seen[u.ID.String()] {
+			continue
+		}
+		seen[u.ID.String()] = true
+		out = append(out, u)
+	}
+	return out
+}
+
+// requesterAdminBlock returns the block a plain admin is restricted to, or "" for chief admins
+// (and anyone else) whose queries are not block scoped.
+func requesterAdminBlock(c *fiber.Ctx) string {
+	role, _ := c.Locals("role").(string)
+	userID, _ := c.Locals("user_id").(string)
+	if role != string(models.Admin) || userID == "" {
+		return ""
+	}
+	var reqUser models.User
+	if err := config.DB.First(&reqUser, "id = ?", userID).Error; err != nil {
+		return ""
+	}
+	return strings.TrimSpace(reqUser.Block)
+}
@@ -0,0 +1,215 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aditisaxena259/mental-health-be/config"
+	"github.com/aditisaxena259/mental-health-be/jobs"
+	"github.com/aditisaxena259/mental-health-be/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var errAppealChanged = errors.New("appeal changed concurrently")
+
+// appealHistoryOrder is a Preload scope listing appeal events oldest first.
+func appealHistoryOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("created_at asc")
+}
+
+// 🧑‍🎓 STUDENT — Appeal a rejected apology to the chief admin (once per apology)
+func AppealApology(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	studentID, err := uuid.Parse(userID)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized: missing user ID"})
+	}
+	var input struct {
+		Reason string `json:"reason"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
+	}
+	input.Reason = strings.TrimSpace(input.Reason)
+	if input.Reason == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "reason is required"})
+	}
+
+	var apology models.Apology
+	if err := config.DB.Preload("Appeal").First(&apology, "id = ? AND student_id = ?", c.Params("id"), studentID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Apology not found"})
+	}
+	if apology.Status != models.ApologyRejected {
+		return c.Status(409).JSON(fiber.Map{"error": "Only rejected apologies can be appealed"})
+	}
+	if apology.Appeal != nil {
+		return c.Status(409).JSON(fiber.Map{"error": "This apology has already been appealed"})
+	}
+
+	appeal := models.ApologyAppeal{
+		ID:        uuid.New(),
+		ApologyID: apology.ID,
+		StudentID: studentID,
+		Reason:    input.Reason,
+		Status:    models.AppealPending,
+	}
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("History").Create(&appeal).Error; err != nil {
+			return err
+		}
+		event := models.ApologyAppealEvent{
+			ID:        uuid.New(),
+			AppealID:  appeal.ID,
+			ToStatus:  models.AppealPending,
+			ActorID:   studentID,
+			ActorRole: string(models.Student),
+			Comment:   input.Reason,
+		}
+		if err := tx.Create(&event).Error; err != nil {
+			return err
+		}
+		// notify chief admins; appeals bypass the block warden
+		return jobs.EnqueueNotification(tx, models.NotificationFanout{
+			Chiefs:      true,
+			Title:       "Apology Appeal Filed",
+			Message:     "A student has appealed a rejected apology: " + appeal.Reason,
+			Type:        "warning",
+			RelatedID:   &appeal.ApologyID,
+			RelatedType: "apology",
+		})
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to file appeal"})
+	}
+	jobs.WakeOutbox()
+
+	config.DB.Preload("History", appealHistoryOrder).First(&appeal, "id = ?", appeal.ID)
+	return c.Status(201).JSON(fiber.Map{"message": "Appeal submitted", "data": appeal})
+}
+
+// 🧑‍💼 CHIEF ADMIN — Appealed apologies, optionally filtered by ?status= of the appeal
+func GetAppeals(c *fiber.Ctx) error {
+	query := config.DB.Preload("Student.User").Preload("Appeal.History", appealHistoryOrder).
+		Joins("JOIN apology_appeals ON apology_appeals.apology_id = apologies.id")
+	if status := c.Query("status"); status != "" {
+		query = query.Where("apology_appeals.status = ?", status)
+	}
+	var apologies []models.Apology
+	if err := query.Order("apology_appeals.created_at asc").Find(&apologies).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch appeals"})
+	}
+	return c.JSON(fiber.Map{"count": len(apologies), "data": apologies})
+}
+
+// 🧑‍💼 CHIEF ADMIN — Move an appeal through review; overturning it accepts the apology
+func DecideAppeal(c *fiber.Ctx) error {
+	var input struct {
+		Status  models.AppealStatus `json:"status"`
+		Comment string              `json:"comment"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
+	}
+	input.Comment = strings.TrimSpace(input.Comment)
+
+	var appeal models.ApologyAppeal
+	if err := config.DB.First(&appeal, "id = ?", c.Params("id")).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Appeal not found"})
+	}
+	if !input.Status.IsValid() {
+		return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("unknown status %q", input.Status)})
+	}
+	if !appeal.Status.CanTransitionTo(input.Status) {
+		return c.Status(409).JSON(fiber.Map{"error": fmt.Sprintf("cannot change appeal from %s to %s", appeal.Status, input.Status)})
+	}
+	if input.Status == models.AppealUpheld && input.Comment == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "A comment is required when upholding a rejection"})
+	}
+
+	role, _ := c.Locals("role").(string)
+	userID, _ := c.Locals("user_id").(string)
+	actorID, _ := uuid.Parse(userID)
+	decided := input.Status == models.AppealUpheld || input.Status == models.AppealOverturned
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		changes := map[string]interface{}{"status": input.Status}
+		if decided {
+			now := time.Now()
+			changes["decided_by_id"] = actorID
+			changes["decided_at"] = now
+			changes["decision_comment"] = input.Comment
+		}
+		res := tx.Model(&models.ApologyAppeal{}).Where("id = ? AND status = ?", appeal.ID, appeal.Status).Updates(changes)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errAppealChanged
+		}
+		if input.Status == models.AppealOverturned {
+			if err := tx.Model(&models.Apology{}).Where("id = ?", appeal.ApologyID).
+				Updates(bumpVersion(map[string]interface{}{"status": models.ApologyAccepted})).Error; err != nil {
+				return err
+			}
+			// the strike is re-derived from the accepted outcome
+			var apology models.Apology
+			if err := tx.First(&apology, "id = ?", appeal.ApologyID).Error; err != nil {
+				return err
+			}
+			pointsBefore, pointsAfter, err := recordStrike(tx, apology, models.ApologyAccepted)
+			if err != nil {
+				return err
+			}
+			if err := notifyDisciplineThreshold(tx, appeal.StudentID, pointsBefore, pointsAfter); err != nil {
+				return err
+			}
+			overturned := newApologyEvent(c, apology.ID, models.ApologyEventStatusChange, models.ApologyRejected, models.ApologyAccepted,
+				strings.TrimSpace("Rejection overturned on appeal. "+input.Comment))
+			if err := tx.Create(&overturned).Error; err != nil {
+				return err
+			}
+		}
+		event := models.ApologyAppealEvent{
+			ID:         uuid.New(),
+			AppealID:   appeal.ID,
+			FromStatus: appeal.Status,
+			ToStatus:   input.Status,
+			ActorID:    actorID,
+			ActorRole:  role,
+			Comment:    input.Comment,
+		}
+		if err := tx.Create(&event).Error; err != nil {
+			return err
+		}
+		if !decided {
+			return nil
+		}
+		note := models.NotificationFanout{
+			UserIDs:     []uuid.UUID{appeal.StudentID},
+			Title:       "Appeal Decided",
+			Message:     "Your appeal was reviewed and the rejection stands: " + input.Comment,
+			Type:        "warning",
+			RelatedID:   &appeal.ApologyID,
+			RelatedType: "apology",
+		}
+		if input.Status == models.AppealOverturned {
+			note.Title, note.Message, note.Type = "Appeal Successful", "Your appeal was successful and your apology has been accepted.", "success"
+		}
+		return jobs.EnqueueNotification(tx, note)
+	})
+	if err == errAppealChanged {
+		return c.Status(409).JSON(fiber.Map{"error": "Appeal was updated by someone else; reload and retry"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update appeal"})
+	}
+
+	jobs.WakeOutbox()
+
+	config.DB.Preload("History", appealHistoryOrder).First(&appeal, "id = ?", appeal.ID)
+	return c.JSON(fiber.Map{"message": "Appeal updated", "data": appeal})
+}
@@ -0,0 +1,256 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aditisaxena259/mental-health-be/config"
+	"github.com/aditisaxena259/mental-health-be/jobs"
+	"github.com/aditisaxena259/mental-health-be/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var errApologyChanged = errors.New("apology changed concurrently")
+
+// newApologyEvent builds a history entry attributed to the requester.
+func newApologyEvent(c *fiber.Ctx, apologyID uuid.UUID, eventType models.ApologyEventType, from, to models.ApologyStatus, comment string) models.ApologyEvent {
+	event := models.ApologyEvent{
+		ID:         uuid.New(),
+		ApologyID:  apologyID,
+		EventType:  eventType,
+		FromStatus: from,
+		ToStatus:   to,
+		Comment:    comment,
+	}
+	event.ActorRole, _ = c.Locals("role").(string)
+	if userID, _ := c.Locals("user_id").(string); userID != "" {
+		if actorID, err := uuid.Parse(userID); err == nil {
+			event.ActorID = &actorID
+		}
+	}
+	return event
+}
+
+// loadOwnApology fetches :id for the requesting student.
+func loadOwnApology(c *fiber.Ctx) (*models.Apology, error) {
+	userID, _ := c.Locals("user_id").(string)
+	var apology models.Apology
+	if err := config.DB.Preload("Student.User").Preload("GuardianAck").
+		First(&apology, "id = ? AND student_id = ?", c.Params("id"), userID).Error; err != nil {
+		return nil, c.Status(404).JSON(fiber.Map{"error": "Apology not found"})
+	}
+	return &apology, nil
+}
+
+// 🧑‍🎓 STUDENT — Edit own apology before review (message, description, structured fields)
+func UpdateOwnApology(c *fiber.Ctx) error {
+	var input struct {
+		Message     *string                `json:"message"`
+		Description *string                `json:"description"`
+		Fields      map[string]interface{} `json:"fields"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
+	}
+
+	apology, errResp := loadOwnApology(c)
+	if apology == nil {
+		return errResp
+	}
+	if !apology.Status.Editable() {
+		return c.Status(409).JSON(fiber.Map{"error": fmt.Sprintf("cannot edit an apology that is %s", apology.Status)})
+	}
+	// the guardian acknowledged this wording; changing it would void the acknowledgement
+	if apology.GuardianAck != nil && apology.GuardianAck.Status == models.GuardianAckAcknowledged {
+		return c.Status(409).JSON(fiber.Map{"error": "cannot edit an apology your guardian has acknowledged; withdraw it and submit a new one instead"})
+	}
+
+	previous := map[string]interface{}{}
+	updates := map[string]interface{}{}
+	changed := []string{}
+	if input.Message != nil && strings.TrimSpace(*input.Message) != apology.Message {
+		if strings.TrimSpace(*input.Message) == "" {
+			return c.Status(400).JSON(fiber.Map{"error": "message cannot be empty"})
+		}
+		previous["message"] = apology.Message
+		apology.Message = strings.TrimSpace(*input.Message)
+		updates["message"] = apology.Message
+		changed = append(changed, "message")
+	}
+	if input.Description != nil && strings.TrimSpace(*input.Description) != apology.Description {
+		previous["description"] = apology.Description
+		apology.Description = strings.TrimSpace(*input.Description)
+		updates["description"] = apology.Description
+		changed = append(changed, "description")
+	}
+
+	// structured fields are re-validated against the template the apology was filed under,
+	// and the letter is re-rendered whenever its inputs change
+	if apology.TemplateID != nil {
+		var tpl models.ApologyTemplate
+		if err := config.DB.First(&tpl, "id = ?", *apology.TemplateID).Error; err != nil {
+			return c.Status(409).JSON(fiber.Map{"error": "The template of this apology no longer exists"})
+		}
+		if input.Fields != nil {
+			values, err := tpl.Validate(input.Fields)
+			if err != nil {
+				return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+			}
+			raw, err := json.Marshal(values)
+			if err != nil {
+				return c.Status(500).JSON(fiber.Map{"error": "Failed to encode fields"})
+			}
+			previous["fields"] = apology.Fields
+			apology.Fields = values
+			updates["fields"] = gorm.Expr("?::jsonb", string(raw))
+			changed = append(changed, "fields")
+		}
+		if len(changed) > 0 {
+			letter := renderApologyLetter(tpl, apology.Student, *apology)
+			if letter != apology.Letter {
+				previous["letter"] = apology.Letter
+				apology.Letter = letter
+				updates["letter"] = letter
+			}
+		}
+	} else if input.Fields != nil {
+		return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("apology type %q has no structured fields", apology.ApologyType)})
+	}
+	if len(changed) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "No changes provided"})
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// the version check keeps an edit from landing after review has started
+		updated, err := updateIfVersion(tx, &models.Apology{}, apology.ID, apology.Version, updates)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			return errApologyChanged
+		}
+		event := newApologyEvent(c, apology.ID, models.ApologyEventEdited, apology.Status, apology.Status,
+			fmt.Sprintf("Apology edited by student (%s)", strings.Join(changed, ", ")))
+		event.ChangedFields = strings.Join(changed, ",")
+		event.Previous = previous
+		return tx.Create(&event).Error
+	})
+	if err == errApologyChanged {
+		return c.Status(409).JSON(fiber.Map{"error": "Apology was updated meanwhile; reload and retry"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update apology"})
+	}
+
+	config.DB.Preload("Student.User").Preload("Attachments").Preload("GuardianAck").First(apology, "id = ?", apology.ID)
+	setETag(c, apology.Version)
+	return c.JSON(fiber.Map{"message": "Apology updated", "data": apology})
+}
+
+// ↩️ STUDENT — Withdraw own apology before review
+func WithdrawApology(c *fiber.Ctx) error {
+	var input struct {
+		Reason string `json:"reason" form:"reason"`
+	}
+	_ = c.BodyParser(&input)
+
+	apology, errResp := loadOwnApology(c)
+	if apology == nil {
+		return errResp
+	}
+	if !apology.Status.CanTransitionTo(models.ApologyWithdrawn) {
+		return c.Status(409).JSON(fiber.Map{"error": fmt.Sprintf("cannot withdraw an apology that is %s", apology.Status)})
+	}
+
+	comment := "Apology withdrawn by student"
+	if r := strings.TrimSpace(input.Reason); r != "" {
+		comment += ": " + r
+	}
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		updated, err := updateIfVersion(tx, &models.Apology{}, apology.ID, apology.Version, map[string]interface{}{"status": models.ApologyWithdrawn})
+		if err != nil {
+			return err
+		}
+		if !updated {
+			return errApologyChanged
+		}
+		// a pending guardian link is no longer needed
+		if err := tx.Model(&models.GuardianAcknowledgement{}).
+			Where("apology_id = ? AND status = ?", apology.ID, models.GuardianAckPending).
+			Update("status", models.GuardianAckExpired).Error; err != nil {
+			return err
+		}
+		event := newApologyEvent(c, apology.ID, models.ApologyEventWithdrawn, apology.Status, models.ApologyWithdrawn, comment)
+		if err := tx.Create(&event).Error; err != nil {
+			return err
+		}
+		// let the block wardens know the apology no longer needs attention
+		return jobs.EnqueueNotification(tx, models.NotificationFanout{
+			Block:       apology.Student.Block,
+			Chiefs:      true,
+			Title:       "Apology Withdrawn",
+			Message:     fmt.Sprintf("%s has withdrawn their %s apology.", apology.Student.User.Name, apology.ApologyType),
+			Type:        "info",
+			RelatedID:   &apology.ID,
+			RelatedType: "apology",
+		})
+	})
+	if err == errApologyChanged {
+		return c.Status(409).JSON(fiber.Map{"error": "Apology was updated meanwhile; reload and retry"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to withdraw apology"})
+	}
+	jobs.WakeOutbox()
+
+	apology.Status = models.ApologyWithdrawn
+	return c.JSON(fiber.Map{"message": "Apology withdrawn", "data": apology})
+}
+
+func apologyHistory(apologyID uuid.UUID) ([]models.ApologyEvent, error) {
+	var events []models.ApologyEvent
+	err := config.DB.Where("apology_id = ?", apologyID).Order("created_at asc").Find(&events).Error
+	return events, err
+}
+
+// 🧑‍🎓 STUDENT — History of own apology
+func GetOwnApologyHistory(c *fiber.Ctx) error {
+	apology, errResp := loadOwnApology(c)
+	if apology == nil {
+		return errResp
+	}
+	events, err := apologyHistory(apology.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load history"})
+	}
+	return c.JSON(fiber.Map{"count": len(events), "data": events})
+}
+
+// 🧑‍💼 ADMIN — History of an apology, including trashed ones. The trail of a purged apology
+// is only visible to the chief admin, as its block can no longer be checked.
+func GetApologyHistory(c *fiber.Ctx) error {
+	apologyID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid apology id"})
+	}
+	var apology models.Apology
+	if err := config.DB.Unscoped().Preload("Student").First(&apology, "id = ?", apologyID).Error; err == nil {
+		if !canAccessStudentBlock(c, apology.Student.Block) {
+			return c.Status(403).JSON(fiber.Map{"error": "Forbidden: admin not authorized for this apology"})
+		}
+	} else if role, _ := c.Locals("role").(string); role != string(models.ChiefAdmin) {
+		return c.Status(404).JSON(fiber.Map{"error": "Apology not found"})
+	}
+	events, err := apologyHistory(apologyID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load history"})
+	}
+	if len(events) == 0 && apology.ID == uuid.Nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Apology not found"})
+	}
+	return c.JSON(fiber.Map{"count": len(events), "data": events})
+}
@@ -1,15 +1,22 @@
 package controllers
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/aditisaxena259/mental-health-be/config"
 	"github.com/aditisaxena259/mental-health-be/helpers"
+	"github.com/aditisaxena259/mental-health-be/jobs"
 	"github.com/aditisaxena259/mental-health-be/models"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // 🧑‍🎓 STUDENT — Submit Apology Letter
@@ -23,11 +30,21 @@ func SubmitApology(c *fiber.Ctx) error {
 		Type        models.ApologyType `form:"type" json:"type"`
 		Message     string             `form:"message" json:"message"`
 		Description string             `form:"description" json:"description"`
+		OutpassID   string             `form:"outpass_id" json:"outpass_id"`
+		// Fields are the structured values of the type's template
+		Fields map[string]interface{} `json:"fields"`
 	}
 	if form, _ := c.MultipartForm(); form != nil {
 		input.Type = models.ApologyType(c.FormValue("type"))
 		input.Message = c.FormValue("message")
 		input.Description = c.FormValue("description")
+		input.OutpassID = c.FormValue("outpass_id")
+		// multipart clients send the structured fields as a JSON object string
+		if raw := c.FormValue("fields"); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &input.Fields); err != nil {
+				return c.Status(400).JSON(fiber.Map{"error": "fields must be a JSON object", "details": err.Error()})
+			}
+		}
 	} else if err := c.BodyParser(&input); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid input", "details": err.Error()})
 	}
@@ -49,122 +66,113 @@ func SubmitApology(c *fiber.Ctx) error {
 		Status:      models.ApologySubmitted,
 	}
 
+	// an apology for a late return is filed against its outpass and is always an outing apology
+	if input.OutpassID != "" {
+		outpass, errResp := loadLateOutpassForApology(c, studentUUID, input.OutpassID)
+		if outpass == nil {
+			return errResp
+		}
+		apology.OutpassID = &outpass.ID
+		apology.ApologyType = models.ApologyForOuting
+	}
+
 	// set StudentIdentifier if available
 	var sm models.StudentModel
-	if err := config.DB.Where("user_id = ?", studentUUID).First(&sm).Error; err == nil {
+	if err := config.DB.Preload("User").Where("user_id = ?", studentUUID).First(&sm).Error; err == nil {
 		// use StudentIdentifier for external mapping
 		// keep StudentID for DB relations
 		apology.StudentIdentifier = sm.StudentIdentifier
 	}
 
-	if err := config.DB.Create(&apology).Error; err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to submit apology", "details": err.Error()})
+	// structured fields are validated against the type's template, which also renders the letter
+	if err := applyApologyTemplate(&apology, sm, input.Fields); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	// If multipart attachments are included, upload to Cloudinary and save records
-	if form, _ := c.MultipartForm(); form != nil {
-		files := form.File["attachments"]
-		if len(files) > 0 {
-			cld, cldErr := helpers.InitCloudinary()
-			// Ensure temp dir exists
-			_ = os.MkdirAll("./uploads/apologies", 0755)
-			for _, fh := range files {
-				if fh == nil {
-					continue
-				}
-				// Only accept JPEGs
-				ext := filepath.Ext(fh.Filename)
-				if ext == "" {
-					ext = ".jpg"
-				}
-				tmpPath := fmt.Sprintf("./uploads/apologies/%s_%s", apology.ID.String(), fh.Filename)
-				if err := c.SaveFile(fh, tmpPath); err != nil {
-					return c.Status(500).JSON(fiber.Map{"error": "Failed to save attachment", "details": err.Error()})
-				}
-				// default values for fallback
-				var fileURL, publicID, sizeStr string
-				// try Cloudinary if configured
-				if cldErr == nil {
-					if uploadRes, upErr := cld.UploadJPEG(tmpPath, "apologies/"+apology.ID.String(), uuid.New().String()); upErr == nil {
-						fileURL = uploadRes.SecureURL
-						publicID = uploadRes.PublicID
-						sizeStr = fmt.Sprintf("%d", uploadRes.Bytes)
-						// cleanup tmp after successful upload
-						_ = os.Remove(tmpPath)
-					} else {
-						// fallback to local file
-						if fi, err := os.Stat(tmpPath); err == nil {
-							sizeStr = fmt.Sprintf("%d", fi.Size())
-						}
-						fileURL = tmpPath
-						publicID = ""
-					}
-				} else {
-					// no Cloudinary configured; fallback to local file path
-					if fi, err := os.Stat(tmpPath); err == nil {
-						sizeStr = fmt.Sprintf("%d", fi.Size())
-					}
-					fileURL = tmpPath
-					publicID = ""
-				}
-				att := models.ApologyAttachment{
-					ID:        uuid.New(),
-					ApologyID: apology.ID,
-					FileName:  fh.Filename,
-					FileURL:   fileURL,
-					PublicID:  publicID,
-					Size:      sizeStr,
-				}
-				if err := config.DB.Create(&att).Error; err != nil {
-					return c.Status(500).JSON(fiber.Map{"error": "Failed to persist attachment", "details": err.Error()})
-				}
-			}
-		}
+	// Validate attachments (optional, field "attachments") before anything is written
+	var files []*multipart.FileHeader
+	if form, err := c.MultipartForm(); err == nil && form != nil {
+		files = form.File["attachments"]
+	}
+	contentTypes, err := checkAttachments(files, apologyAttachmentTypes)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	// create notifications for admins of the student's block + chief admins
-	go func(a models.Apology) {
-		var sm models.StudentModel
-		if err := config.DB.Where("user_id = ?", a.StudentID).First(&sm).Error; err != nil {
-			return
-		}
-		block := sm.Block
-		var admins []models.User
-		if block != "" {
-			config.DB.Where("role = ? AND block = ?", models.Admin, block).Find(&admins)
-		}
-		var chiefs []models.User
-		config.DB.Where("role = ?", models.ChiefAdmin).Find(&chiefs)
-		// deduplicate
-		m := map[string]models.User{}
-		for _, a := range admins {
-			m[a.ID.String()] = a
+	// types that need a guardian acknowledgement wait for it before review; without a guardian
+	// contact the apology stays on hold until the warden adds one
+	guardianCfg := jobs.GuardianConfigFromEnv()
+	needsGuardian := guardianCfg.Requires(apology.ApologyType)
+	if needsGuardian {
+		apology.Status = models.ApologyAwaitingGuardian
+	}
+
+	// the apology and its attachments are stored together or not at all
+	tx := config.DB.Begin()
+	if err := tx.Create(&apology).Error; err != nil {
+		tx.Rollback()
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to submit apology", "details": err.Error()})
+	}
+	event := newApologyEvent(c, apology.ID, models.ApologyEventSubmitted, "", apology.Status, "Apology submitted")
+	if err := tx.Create(&event).Error; err != nil {
+		tx.Rollback()
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to submit apology", "details": err.Error()})
+	}
+	if needsGuardian {
+		ack := newGuardianAck(guardianCfg, apology, sm)
+		if err := tx.Create(&ack).Error; err != nil {
+			tx.Rollback()
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to submit apology", "details": err.Error()})
 		}
-		for _, c := range chiefs {
-			m[c.ID.String()] = c
+	}
+	uploaded := []models.ApologyAttachment{}
+	for i, fh := range files {
+		if fh == nil {
+			continue
 		}
-		admins = []models.User{}
-		for _, v := range m {
-			admins = append(admins, v)
+		att, saveErr := uploadApologyAttachment(fh, apology.ID, contentTypes[i])
+		if saveErr != nil {
+			tx.Rollback()
+			discardApologyUploads(uploaded)
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to save attachment", "details": saveErr.Error()})
 		}
-		related := a.ID
-		rtype := "apology"
-		for _, adm := range admins {
-			n := models.Notification{
-				ID:          uuid.New(),
-				UserID:      adm.ID,
-				Title:       "New Apology Submitted",
-				Message:     "A student has submitted an apology: " + a.Message,
-				Type:        "info",
-				RelatedID:   &related,
-				RelatedType: &rtype,
-			}
-			config.DB.Create(&n)
+		uploaded = append(uploaded, att)
+		if err := tx.Create(&att).Error; err != nil {
+			tx.Rollback()
+			discardApologyUploads(uploaded)
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to persist attachment", "details": err.Error()})
 		}
-	}(apology)
+	}
+	// notify admins of the student's block + chief admins together with the apology
+	related := apology.ID
+	message := "A student has submitted an apology: " + apology.Message
+	if needsGuardian && !sm.HasGuardianContact() {
+		message += " It is on hold until a guardian contact is added for the student."
+	}
+	if err := jobs.EnqueueNotification(tx, models.NotificationFanout{
+		Block:       sm.Block,
+		Chiefs:      true,
+		Title:       "New Apology Submitted",
+		Message:     message,
+		Type:        "info",
+		RelatedID:   &related,
+		RelatedType: "apology",
+	}); err != nil {
+		tx.Rollback()
+		discardApologyUploads(uploaded)
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to submit apology", "details": err.Error()})
+	}
+	if err := tx.Commit().Error; err != nil {
+		discardApologyUploads(uploaded)
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to submit apology", "details": err.Error()})
+	}
+	jobs.WakeOutbox()
+	if needsGuardian {
+		jobs.WakeGuardianAckMonitor()
+	}
 
 	// ✅ Preload after creation so response includes Student details and attachments
-	config.DB.Preload("Student.User").Preload("Attachments").First(&apology, "id = ?", apology.ID)
+	config.DB.Preload("Student.User").Preload("Attachments").Preload("GuardianAck").First(&apology, "id = ?", apology.ID)
 
 	return c.JSON(fiber.Map{
 		"message": "Apology letter submitted successfully",
@@ -172,6 +180,70 @@ func SubmitApology(c *fiber.Ctx) error {
 	})
 }
 
+// uploadApologyAttachment stores a validated file as ./uploads/apologies/<apologyID>_<rand>_<name>,
+// then pushes it to Cloudinary when configured (PDFs are accepted there as image assets).
+// The returned record is not persisted; callers create it in their transaction.
+func uploadApologyAttachment(fh *multipart.FileHeader, apologyID uuid.UUID, contentType string) (models.ApologyAttachment, error) {
+	if err := os.MkdirAll("./uploads/apologies", 0755); err != nil {
+		return models.ApologyAttachment{}, err
+	}
+	name := sanitizeFilename(fh.Filename, contentType)
+	// the random segment keeps same-named files of one apology apart
+	stored := fmt.Sprintf("%s_%s_%s", apologyID, uuid.NewString()[:8], name)
+	localPath := filepath.Join("./uploads/apologies", stored)
+
+	src, err := fh.Open()
+	if err != nil {
+		return models.ApologyAttachment{}, err
+	}
+	defer src.Close()
+	dst, err := os.Create(localPath)
+	if err != nil {
+		return models.ApologyAttachment{}, err
+	}
+	written, err := io.Copy(dst, src)
+	dst.Close()
+	if err != nil {
+		_ = os.Remove(localPath)
+		return models.ApologyAttachment{}, err
+	}
+
+	// Fallback: keep local, served by app.Static("/uploads", "./uploads")
+	att := models.ApologyAttachment{
+		ID:          uuid.New(),
+		ApologyID:   apologyID,
+		FileName:    name,
+		ContentType: contentType,
+		FileURL:     "/uploads/apologies/" + stored,
+		Size:        fmt.Sprintf("%d", written),
+	}
+	if cld, cldErr := helpers.InitCloudinary(); cldErr == nil {
+		if upRes, upErr := cld.UploadJPEG(localPath, "apologies/"+apologyID.String(), uuid.New().String()); upErr == nil {
+			att.FileURL = upRes.SecureURL
+			att.PublicID = upRes.PublicID
+			att.Size = fmt.Sprintf("%d", upRes.Bytes)
+			_ = os.Remove(localPath)
+		}
+	}
+	return att, nil
+}
+
+// discardApologyUploads removes files stored for attachments whose apology was rolled back.
+func discardApologyUploads(atts []models.ApologyAttachment) {
+	cld, cldErr := helpers.InitCloudinary()
+	for _, a := range atts {
+		if a.PublicID != "" {
+			if cldErr == nil {
+				_ = cld.Destroy(a.PublicID)
+			}
+			continue
+		}
+		if strings.HasPrefix(a.FileURL, "/uploads/apologies/") {
+			_ = os.Remove("." + a.FileURL)
+		}
+	}
+}
+
 // 🧑‍🎓 STUDENT — Get Own Apologies
 func GetStudentApologies(c *fiber.Ctx) error {
 	userID, ok := c.Locals("user_id").(string)
@@ -187,7 +259,7 @@ func GetStudentApologies(c *fiber.Ctx) error {
 
 	var apologies []models.Apology
 	if err := config.DB.
-		Preload("Student.User").Preload("Attachments").
+		Preload("Student.User").Preload("Attachments").Preload("GuardianAck").Preload("Appeal.History", appealHistoryOrder).
 		Where("student_id = ?", sid.String()).
 		Order("created_at desc").
 		Find(&apologies).Error; err != nil {
@@ -206,44 +278,62 @@ func GetStudentApologies(c *fiber.Ctx) error {
 // 🧑‍💼 ADMIN — Get All or Filtered Apologies
 func GetApologies(c *fiber.Ctx) error {
 	var apologies []models.Apology
-	role, _ := c.Locals("role").(string)
-	userID, _ := c.Locals("user_id").(string)
-	query := config.DB.Preload("Student.User").Preload("Attachments")
+	query := config.DB.Preload("Student.User").Preload("Attachments").Preload("GuardianAck").Preload("Appeal.History", appealHistoryOrder).Scopes(apologyFilterScope(c))
 
-	if apologyType := c.Query("type"); apologyType != "" {
-		query = query.Where("apology_type = ?", apologyType)
-	}
-	if status := c.Query("status"); status != "" {
-		query = query.Where("status = ?", status)
-	}
-
-	// Block admin: only see apologies for their block
-	if role == string(models.Admin) && userID != "" {
-		var reqUser models.User
-		if err := config.DB.First(&reqUser, "id = ?", userID).Error; err == nil {
-			if reqUser.Block != "" {
-				query = query.Joins("LEFT JOIN student_models ON student_models.user_id = apologies.student_id").
-					Where("student_models.block = ?", reqUser.Block)
-			}
-		}
-	}
-
-	if err := query.Order("created_at desc").Find(&apologies).Error; err != nil {
+	if err := query.Order("apologies.created_at desc").Find(&apologies).Error; err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch apologies"})
 	}
 
 	return c.JSON(fiber.Map{"count": len(apologies), "data": apologies})
 }
 
+// apologyFilterScope applies the admin apology list filters (?type, ?status, ?block, ?from, ?to)
+// and restricts block admins to apologies from their own block.
+func apologyFilterScope(c *fiber.Ctx) func(db *gorm.DB) *gorm.DB {
+	adminBlock := requesterAdminBlock(c)
+	role, _ := c.Locals("role").(string)
+	apologyType, status, block := c.Query("type"), c.Query("status"), c.Query("block")
+	from, fromErr := time.Parse("2006-01-02", c.Query("from"))
+	to, toErr := time.Parse("2006-01-02", c.Query("to"))
+	return func(db *gorm.DB) *gorm.DB {
+		if apologyType != "" {
+			db = db.Where("apologies.apology_type = ?", apologyType)
+		}
+		if status != "" {
+			db = db.Where("apologies.status = ?", status)
+		}
+		if block != "" {
+			db = db.Where("apologies.student_id IN (SELECT user_id FROM student_models WHERE block = ?)", strings.ToUpper(block))
+		}
+		if fromErr == nil {
+			db = db.Where("apologies.created_at >= ?", from)
+		}
+		if toErr == nil {
+			db = db.Where("apologies.created_at < ?", to.AddDate(0, 0, 1))
+		}
+		// Block admin: only see apologies for their block
+		if adminBlock != "" {
+			db = db.Where("apologies.student_id IN (SELECT user_id FROM student_models WHERE block = ?)", adminBlock)
+		} else if role == string(models.Admin) {
+			db = db.Where("1 = 0")
+		}
+		return db
+	}
+}
+
 // 🧑‍💼 ADMIN — Get Apology by ID
 func GetApologyByID(c *fiber.Ctx) error {
 	id := c.Params("id")
 	var apology models.Apology
 
-	if err := config.DB.Preload("Student.User").Preload("Attachments").First(&apology, "id = ?", id).Error; err != nil {
+	if err := config.DB.Preload("Student.User").Preload("Attachments").Preload("GuardianAck").Preload("Appeal.History", appealHistoryOrder).First(&apology, "id = ?", id).Error; err != nil {
 		return c.Status(404).JSON(fiber.Map{"error": "Apology not found"})
 	}
+	if !canAccessStudentBlock(c, apology.Student.Block) {
+		return c.Status(403).JSON(fiber.Map{"error": "Forbidden: admin not authorized for this apology"})
+	}
 
+	setETag(c, apology.Version)
 	return c.JSON(apology)
 }
 
@@ -268,59 +358,94 @@ func ReviewApology(c *fiber.Ctx) error {
 	}()
 
 	var apology models.Apology
-	if err := tx.First(&apology, "id = ?", id).Error; err != nil {
+	if err := tx.Preload("Student").First(&apology, "id = ?", id).Error; err != nil {
 		tx.Rollback()
 		return c.Status(404).JSON(fiber.Map{"error": "Apology not found"})
 	}
+	// Only the warden of the student's block or the chief admin may review
+	if !canAccessStudentBlock(c, apology.Student.Block) {
+		tx.Rollback()
+		return c.Status(403).JSON(fiber.Map{"error": "Forbidden: admin not authorized to review this apology"})
+	}
+	version, ok := ifMatchVersion(c, apology.Version)
+	if !ok {
+		tx.Rollback()
+		return preconditionRequired(c)
+	}
+	if !input.Status.IsValid() {
+		tx.Rollback()
+		return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("unknown status %q", input.Status)})
+	}
+	// submitted follows the guardian acknowledgement and withdrawn is the student's call
+	if input.Status != models.ApologyReviewed && input.Status != models.ApologyAccepted && input.Status != models.ApologyRejected {
+		tx.Rollback()
+		return c.Status(400).JSON(fiber.Map{"error": "status must be reviewed, accepted or rejected"})
+	}
+	if !apology.Status.CanTransitionTo(input.Status) {
+		tx.Rollback()
+		return c.Status(409).JSON(fiber.Map{"error": fmt.Sprintf("cannot change status from %s to %s", apology.Status, input.Status)})
+	}
+	input.Comment = strings.TrimSpace(input.Comment)
+	if input.Status == models.ApologyRejected && input.Comment == "" {
+		tx.Rollback()
+		return c.Status(400).JSON(fiber.Map{"error": "A comment is required when rejecting an apology"})
+	}
+
+	changes := map[string]interface{}{"status": input.Status}
+	if input.Comment != "" {
+		changes["comment"] = input.Comment
+	}
+	updated, err := updateIfVersion(tx, &models.Apology{}, apology.ID, version, changes)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update apology"})
+	}
+	if !updated {
+		tx.Rollback()
+		return preconditionFailed(c, apology.Version)
+	}
+	event := newApologyEvent(c, apology.ID, models.ApologyEventStatusChange, apology.Status, input.Status, input.Comment)
+	if err := tx.Create(&event).Error; err != nil {
+		tx.Rollback()
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update apology"})
+	}
 
-	apology.Status = input.Status
-	apology.Comment = input.Comment
+	// Decided outing/misconduct apologies go on the student's disciplinary record
+	pointsBefore, pointsAfter := 0, 0
+	if input.Status == models.ApologyAccepted || input.Status == models.ApologyRejected {
+		if pointsBefore, pointsAfter, err = recordStrike(tx, apology, input.Status); err != nil {
+			tx.Rollback()
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to record disciplinary strike"})
+		}
+		if err := notifyDisciplineThreshold(tx, apology.StudentID, pointsBefore, pointsAfter); err != nil {
+			tx.Rollback()
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to update apology"})
+		}
+	}
 
-	if err := tx.Save(&apology).Error; err != nil {
+	// Notify student about apology status change (reviewed/accepted/rejected)
+	note := models.NotificationFanout{UserIDs: []uuid.UUID{apology.StudentID}, RelatedID: &apology.ID, RelatedType: "apology"}
+	switch input.Status {
+	case models.ApologyReviewed:
+		note.Title, note.Message, note.Type = "Apology Under Review", "Your apology letter is being reviewed by the warden.", "info"
+	case models.ApologyAccepted:
+		note.Title, note.Message, note.Type = "Apology Accepted", "Your apology has been accepted.", "success"
+	case models.ApologyRejected:
+		note.Title, note.Message, note.Type = "Apology Rejected", "Your apology has been rejected. You may appeal the decision once to the chief warden.", "warning"
+	}
+	if err := jobs.EnqueueNotification(tx, note); err != nil {
 		tx.Rollback()
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to update apology"})
 	}
 
-	tx.Commit()
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update apology"})
+	}
+	jobs.WakeOutbox()
 
 	// ✅ Load Student details for the response
 	config.DB.Preload("Student.User").First(&apology, "id = ?", id)
-
-	// Notify student about apology status change (reviewed/accepted/rejected)
-	go func(a models.Apology, status models.ApologyStatus) {
-		var title, message, ntype string
-		switch status {
-		case models.ApologySubmitted:
-			return
-		case models.ApologyReviewed:
-			title = "Apology Under Review"
-			message = "Your apology letter is being reviewed by the warden."
-			ntype = "info"
-		case models.ApologyAccepted:
-			title = "Apology Accepted"
-			message = "Your apology has been accepted."
-			ntype = "success"
-		case models.ApologyRejected:
-			title = "Apology Rejected"
-			message = "Your apology has been rejected. Please contact the warden for details."
-			ntype = "warning"
-		default:
-			return
-		}
-
-		related := a.ID
-		rtype := "apology"
-		n := models.Notification{
-			ID:          uuid.New(),
-			UserID:      a.StudentID,
-			Title:       title,
-			Message:     message,
-			Type:        ntype,
-			RelatedID:   &related,
-			RelatedType: &rtype,
-		}
-		config.DB.Create(&n)
-	}(apology, input.Status)
+	setETag(c, apology.Version)
 
 	return c.JSON(fiber.Map{
 		"message": "Apology reviewed successfully",
@@ -331,7 +456,8 @@ func ReviewApology(c *fiber.Ctx) error {
 // 🧾 ADMIN — Pending Count
 func GetPendingApology(c *fiber.Ctx) error {
 	var count int64
-	if err := config.DB.Model(&models.Apology{}).Where("status = ?", models.ApologySubmitted).Count(&count).Error; err != nil {
+	if err := config.DB.Model(&models.Apology{}).Where("apologies.status IN ?", inboxApologyStatuses).
+		Scopes(apologyFilterScope(c)).Count(&count).Error; err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to count pending apologies"})
 	}
 	return c.JSON(fiber.Map{"pending_count": count})
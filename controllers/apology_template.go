@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aditisaxena259/mental-health-be/config"
+	"github.com/aditisaxena259/mental-health-be/helpers"
+	"github.com/aditisaxena259/mental-health-be/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// activeApologyTemplate returns the active template for an apology type, or nil when there is none.
+func activeApologyTemplate(apologyType models.ApologyType) *models.ApologyTemplate {
+	var tpl models.ApologyTemplate
+	if err := config.DB.Where("apology_type = ? AND is_active = ?", apologyType, true).First(&tpl).Error; err != nil {
+		return nil
+	}
+	return &tpl
+}
+
+// applyApologyTemplate validates the structured fields of a new apology against its type's
+// template and renders the letter. Types without a template take free text only.
+func applyApologyTemplate(apology *models.Apology, sm models.StudentModel, fields map[string]interface{}) error {
+	tpl := activeApologyTemplate(apology.ApologyType)
+	if tpl == nil {
+		if len(fields) > 0 {
+			return fmt.Errorf("apology type %q has no structured fields", apology.ApologyType)
+		}
+		return nil
+	}
+	values, err := tpl.Validate(fields)
+	if err != nil {
+		return err
+	}
+	apology.TemplateID = &tpl.ID
+	apology.Fields = values
+	apology.Letter = renderApologyLetter(*tpl, sm, *apology)
+	return nil
+}
+
+// renderApologyLetter fills the template with the student's details and the apology's message and fields.
+func renderApologyLetter(tpl models.ApologyTemplate, sm models.StudentModel, apology models.Apology) string {
+	return tpl.Render(map[string]string{
+		"student_name":       sm.User.Name,
+		"student_identifier": sm.StudentIdentifier,
+		"block":              sm.Block,
+		"room_no":            sm.RoomNo,
+		"date":               time.Now().Format("02 January 2006"),
+		"message":            apology.Message,
+	}, apology.Fields)
+}
+
+// 📝 Apology templates per type; only chief admins see inactive ones
+func GetApologyTemplates(c *fiber.Ctx) error {
+	query := config.DB.Order("apology_type")
+	if role, _ := c.Locals("role").(string); role != string(models.ChiefAdmin) {
+		query = query.Where("is_active = ?", true)
+	}
+	var templates []models.ApologyTemplate
+	if err := query.Find(&templates).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch apology templates"})
+	}
+	return c.JSON(fiber.Map{"count": len(templates), "data": templates})
+}
+
+// 🧑‍💼 CHIEF ADMIN — Create or replace the template of an apology type
+func UpsertApologyTemplate(c *fiber.Ctx) error {
+	apologyType := models.ApologyType(c.Params("type"))
+	if !apologyType.IsValid() {
+		return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("unknown apology type %q", apologyType)})
+	}
+	var input struct {
+		Title    string                        `json:"title"`
+		Body     string                        `json:"body"`
+		Fields   []models.ApologyTemplateField `json:"fields"`
+		IsActive *bool                         `json:"is_active"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid input", "details": err.Error()})
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	editorID, _ := uuid.Parse(userID)
+	var tpl models.ApologyTemplate
+	created := config.DB.Where("apology_type = ?", apologyType).First(&tpl).Error != nil
+	if created {
+		tpl = models.ApologyTemplate{ID: uuid.New(), ApologyType: apologyType, IsActive: true}
+	}
+	tpl.Title = strings.TrimSpace(input.Title)
+	tpl.Body = input.Body
+	tpl.Fields = input.Fields
+	if tpl.Fields == nil {
+		tpl.Fields = []models.ApologyTemplateField{}
+	}
+	for i := range tpl.Fields {
+		tpl.Fields[i].Label = strings.TrimSpace(tpl.Fields[i].Label)
+		if tpl.Fields[i].Label == "" {
+			tpl.Fields[i].Label = tpl.Fields[i].Key
+		}
+	}
+	if input.IsActive != nil {
+		tpl.IsActive = *input.IsActive
+	}
+	tpl.UpdatedByID = &editorID
+	if err := tpl.CheckDefinition(); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := config.DB.Save(&tpl).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to save apology template"})
+	}
+	status := 200
+	if created {
+		status = 201
+	}
+	return c.Status(status).JSON(fiber.Map{"message": "Apology template saved", "data": tpl})
+}
+
+// 🧑‍💼 CHIEF ADMIN — Remove the template of an apology type; it goes back to free text
+func DeleteApologyTemplate(c *fiber.Ctx) error {
+	res := config.DB.Where("apology_type = ?", c.Params("type")).Delete(&models.ApologyTemplate{})
+	if res.Error != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete apology template"})
+	}
+	if res.RowsAffected == 0 {
+		return c.Status(404).JSON(fiber.Map{"error": "Apology template not found"})
+	}
+	return c.JSON(fiber.Map{"message": "Apology template deleted"})
+}
+
+// sendApologyLetter renders an apology as a PDF letter, labelled by the template it was filed under.
+func sendApologyLetter(c *fiber.Ctx, apology models.Apology) error {
+	subject := ""
+	var fields []models.ApologyTemplateField
+	if apology.TemplateID != nil {
+		var tpl models.ApologyTemplate
+		if err := config.DB.First(&tpl, "id = ?", *apology.TemplateID).Error; err == nil {
+			subject, fields = tpl.Title, tpl.Fields
+		}
+	}
+	var buf bytes.Buffer
+	if err := helpers.CreateApologyLetter(&buf, apology, subject, fields); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to generate letter", "details": err.Error()})
+	}
+	return sendPDF(c, fmt.Sprintf("apology-%s.pdf", apology.ID), &buf)
+}
+
+// 🧑‍🎓 STUDENT — Download own apology as a PDF letter
+func GetOwnApologyLetter(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	var apology models.Apology
+	if err := config.DB.Preload("Student.User").First(&apology, "id = ? AND student_id = ?", c.Params("id"), userID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Apology not found"})
+	}
+	return sendApologyLetter(c, apology)
+}
+
+// 🧑‍💼 ADMIN — Download an apology as a PDF letter
+func GetApologyLetter(c *fiber.Ctx) error {
+	var apology models.Apology
+	if err := config.DB.Preload("Student.User").First(&apology, "id = ?", c.Params("id")).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Apology not found"})
+	}
+	if !canAccessStudentBlock(c, apology.Student.Block) {
+		return c.Status(403).JSON(fiber.Map{"error": "Forbidden: admin not authorized for this apology"})
+	}
+	return sendApologyLetter(c, apology)
+}
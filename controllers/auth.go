@@ -27,8 +27,8 @@ func Signup(c *fiber.Ctx) error {
 	// Require room and hostel/block depending on role
 	role := models.RoleType(data["role"])
 	email := data["email"]
-	if role == models.Admin {
-		// admin must provide block
+	if role == models.Admin || role == models.Security {
+		// admin and gate security staff must provide block
 		if data["block"] == "" {
 			return c.Status(400).JSON(fiber.Map{"error": "Hostel block is required for admin signups"})
 		}
@@ -0,0 +1,217 @@
+package controllers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/aditisaxena259/mental-health-be/config"
+	"github.com/aditisaxena259/mental-health-be/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// resolveCategory looks up an active top-level category by key (or id) and, when given,
+// an active subcategory of it.
+func resolveCategory(key, subKey string) (*models.ComplaintCategory, *models.ComplaintCategory, error) {
+	var category models.ComplaintCategory
+	query := config.DB.Where("parent_id IS NULL AND is_active = ?", true)
+	if id, err := uuid.Parse(key); err == nil {
+		query = query.Where("id = ?", id)
+	} else {
+		query = query.Where("key = ?", key)
+	}
+	if err := query.First(&category).Error; err != nil {
+		return nil, nil, errors.New("unknown or inactive complaint type: " + key)
+	}
+	if subKey == "" {
+		return &category, nil, nil
+	}
+
+	var sub models.ComplaintCategory
+	subQuery := config.DB.Where("parent_id = ? AND is_active = ?", category.ID, true)
+	if id, err := uuid.Parse(subKey); err == nil {
+		subQuery = subQuery.Where("id = ?", id)
+	} else {
+		subQuery = subQuery.Where("key = ?", subKey)
+	}
+	if err := subQuery.First(&sub).Error; err != nil {
+		return nil, nil, errors.New("unknown or inactive subcategory for " + category.Name + ": " + subKey)
+	}
+	return &category, &sub, nil
+}
+
+type categoryInput struct {
+	Key                *string `json:"key"`
+	Name               *string `json:"name"`
+	ParentID           *string `json:"parent_id"`
+	DefaultPriority    *string `json:"default_priority"`
+	SLAHours           *int    `json:"sla_hours"`
+	AllowsConfidential *bool   `json:"allows_confidential"`
+	IsActive           *bool   `json:"is_active"`
+	SortOrder          *int    `json:"sort_order"`
+}
+
+// apply copies the provided fields onto cat and validates the result.
+func (in categoryInput) apply(cat *models.ComplaintCategory) error {
+	if in.Key != nil {
+		cat.Key = strings.TrimSpace(*in.Key)
+	}
+	if in.Name != nil {
+		cat.Name = strings.TrimSpace(*in.Name)
+	}
+	if in.ParentID != nil {
+		if *in.ParentID == "" {
+			cat.ParentID = nil
+		} else {
+			pid, err := uuid.Parse(*in.ParentID)
+			if err != nil {
+				return errors.New("parent_id must be a valid category id")
+			}
+			var parent models.ComplaintCategory
+			if err := config.DB.First(&parent, "id = ?", pid).Error; err != nil {
+				return errors.New("parent category not found")
+			}
+			if parent.ParentID != nil {
+				return errors.New("subcategories cannot be nested more than one level")
+			}
+			if pid == cat.ID {
+				return errors.New("a category cannot be its own parent")
+			}
+			cat.ParentID = &pid
+		}
+	}
+	if in.DefaultPriority != nil {
+		cat.DefaultPriority = models.ComplaintPriority(*in.DefaultPriority)
+	}
+	if in.SLAHours != nil {
+		cat.SLAHours = *in.SLAHours
+	}
+	if in.AllowsConfidential != nil {
+		cat.AllowsConfidential = *in.AllowsConfidential
+	}
+	if in.IsActive != nil {
+		cat.IsActive = *in.IsActive
+	}
+	if in.SortOrder != nil {
+		cat.SortOrder = *in.SortOrder
+	}
+
+	if cat.Key == "" || cat.Name == "" {
+		return errors.New("key and name are required")
+	}
+	if !isValidPriority(cat.DefaultPriority) {
+		return errors.New("default_priority must be one of low, medium, high")
+	}
+	if cat.SLAHours < 0 {
+		return errors.New("sla_hours cannot be negative")
+	}
+	return nil
+}
+
+// 🗂️ ALL USERS — List complaint categories with their subcategories
+// Inactive categories are only included for staff passing ?include_inactive=true.
+func GetCategories(c *fiber.Ctx) error {
+	role, _ := c.Locals("role").(string)
+	includeInactive := c.Query("include_inactive") == "true" && role != string(models.Student)
+
+	subScope := config.DB.Order("sort_order asc, name asc")
+	query := config.DB.Where("parent_id IS NULL").Order("sort_order asc, name asc")
+	if !includeInactive {
+		query = query.Where("is_active = ?", true)
+		subScope = subScope.Where("is_active = ?", true)
+	}
+
+	var categories []models.ComplaintCategory
+	if err := query.Preload("Subcategories", subScope).Find(&categories).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch categories"})
+	}
+	return c.JSON(fiber.Map{"count": len(categories), "data": categories})
+}
+
+// 🗂️ CHIEF ADMIN — Create a category or subcategory
+func CreateCategory(c *fiber.Ctx) error {
+	var input categoryInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
+	}
+	cat := models.ComplaintCategory{ID: uuid.New(), DefaultPriority: models.PriorityMedium, IsActive: true}
+	if err := input.apply(&cat); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var existing int64
+	config.DB.Model(&models.ComplaintCategory{}).Where("key = ?", cat.Key).Count(&existing)
+	if existing > 0 {
+		return c.Status(409).JSON(fiber.Map{"error": "A category with this key already exists"})
+	}
+	if err := config.DB.Create(&cat).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create category", "details": err.Error()})
+	}
+	return c.Status(201).JSON(fiber.Map{"message": "Category created", "data": cat})
+}
+
+// 🗂️ CHIEF ADMIN — Update a category
+// Renaming the key also rewrites Complaint.Type on complaints filed under it.
+func UpdateCategory(c *fiber.Ctx) error {
+	var input categoryInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
+	}
+	var cat models.ComplaintCategory
+	if err := config.DB.First(&cat, "id = ?", c.Params("id")).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Category not found"})
+	}
+	oldKey := cat.Key
+	if err := input.apply(&cat); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if cat.ParentID != nil {
+		var children int64
+		config.DB.Model(&models.ComplaintCategory{}).Where("parent_id = ?", cat.ID).Count(&children)
+		if children > 0 {
+			return c.Status(400).JSON(fiber.Map{"error": "A category with subcategories cannot become a subcategory"})
+		}
+	}
+	if cat.Key != oldKey {
+		var existing int64
+		config.DB.Model(&models.ComplaintCategory{}).Where("key = ? AND id <> ?", cat.Key, cat.ID).Count(&existing)
+		if existing > 0 {
+			return c.Status(409).JSON(fiber.Map{"error": "A category with this key already exists"})
+		}
+	}
+
+	tx := config.DB.Begin()
+	if err := tx.Save(&cat).Error; err != nil {
+		tx.Rollback()
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update category", "details": err.Error()})
+	}
+	if cat.Key != oldKey && cat.ParentID == nil {
+		if err := tx.Unscoped().Model(&models.Complaint{}).Where("category_id = ?", cat.ID).Update("type", cat.Key).Error; err != nil {
+			tx.Rollback()
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to update complaints for renamed category"})
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update category", "details": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Category updated", "data": cat})
+}
+
+// 🗂️ CHIEF ADMIN — Delete a category
+// Categories already used by complaints can only be deactivated, not deleted.
+func DeleteCategory(c *fiber.Ctx) error {
+	var cat models.ComplaintCategory
+	if err := config.DB.First(&cat, "id = ?", c.Params("id")).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Category not found"})
+	}
+	var used, children int64
+	config.DB.Unscoped().Model(&models.Complaint{}).Where("category_id = ? OR subcategory_id = ?", cat.ID, cat.ID).Count(&used)
+	config.DB.Model(&models.ComplaintCategory{}).Where("parent_id = ?", cat.ID).Count(&children)
+	if used > 0 || children > 0 {
+		return c.Status(409).JSON(fiber.Map{"error": "Category is in use; deactivate it instead"})
+	}
+	if err := config.DB.Delete(&cat).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete category"})
+	}
+	return c.JSON(fiber.Map{"message": "Category deleted"})
+}
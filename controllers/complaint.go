@@ -1,10 +1,10 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
-	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -12,6 +12,7 @@ import (
 
 	"github.com/aditisaxena259/mental-health-be/config"
 	"github.com/aditisaxena259/mental-health-be/helpers"
+	"github.com/aditisaxena259/mental-health-be/jobs"
 	"github.com/aditisaxena259/mental-health-be/models"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -35,17 +36,48 @@ func CreateComplaint(c *fiber.Ctx) error {
 		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized: missing user ID"})
 	}
 
+	// type must name an active category; subcategory is optional and must belong to it
+	category, subcategory, catErr := resolveCategory(ctype, c.FormValue("subcategory"))
+	if catErr != nil {
+		return c.Status(400).JSON(fiber.Map{"error": catErr.Error()})
+	}
+
 	complaint := models.Complaint{
 		ID:          uuid.New(),
 		Title:       title,
-		Type:        models.ComplaintType(ctype),
+		Type:        models.ComplaintType(category.Key),
+		CategoryID:  &category.ID,
 		Description: description,
 		UserID:      uuid.MustParse(userID),
 		Status:      models.Open,
+		Priority:    category.DefaultPriority,
+	}
+	slaHours := category.SLAHours
+	if subcategory != nil {
+		complaint.SubcategoryID = &subcategory.ID
+		complaint.Priority = subcategory.DefaultPriority
+		if subcategory.SLAHours > 0 {
+			slaHours = subcategory.SLAHours
+		}
+	}
+	if slaHours > 0 {
+		due := time.Now().Add(time.Duration(slaHours) * time.Hour)
+		complaint.DueAt = &due
+	}
+
+	// confidential complaints hide the student's identity from wardens
+	if v := strings.ToLower(c.FormValue("confidential")); v == "true" || v == "1" {
+		if !category.AllowsConfidential {
+			return c.Status(400).JSON(fiber.Map{"error": "This category cannot be filed confidentially"})
+		}
+		complaint.IsConfidential = true
 	}
 
 	// set priority if provided
 	if priorityStr != "" {
+		if !isValidPriority(models.ComplaintPriority(priorityStr)) {
+			return c.Status(400).JSON(fiber.Map{"error": "priority must be one of low, medium, high"})
+		}
 		complaint.Priority = models.ComplaintPriority(priorityStr)
 	}
 
@@ -55,6 +87,20 @@ func CreateComplaint(c *fiber.Ctx) error {
 		complaint.StudentIdentifier = sm.StudentIdentifier
 	}
 
+	// optional room/asset references; default the room to the student's own
+	if err := linkComplaintRoom(&complaint, sm, c.FormValue("room_id"), c.FormValue("asset_id")); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// Validate attachments (optional, field "attachments") before anything is written
+	var files []*multipart.FileHeader
+	if form, err := c.MultipartForm(); err == nil && form != nil {
+		files = form.File["attachments"]
+	}
+	if _, err := checkAttachments(files, complaintAttachmentTypes); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
 	// Start transaction
 	tx := config.DB.Begin()
 	if err := tx.Create(&complaint).Error; err != nil {
@@ -62,120 +108,77 @@ func CreateComplaint(c *fiber.Ctx) error {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to create complaint", "details": err.Error()})
 	}
 
-	// NOTE: defer creating notifications until after the DB transaction commits
-	// to avoid creating notifications for complaints that later rollback (e.g. attachment failures).
-
 	// Handle attachments (optional). Field name: "attachments" (multiple)
-	form, err := c.MultipartForm()
-	if err == nil && form != nil {
-		files := form.File["attachments"]
-		for _, fh := range files {
-			if fh == nil {
-				continue
-			}
-			if !isJPEG(fh) {
-				tx.Rollback()
-				return c.Status(400).JSON(fiber.Map{"error": "Only JPEG attachments are allowed"})
-			}
-			// Save temporarily to disk then upload to Cloudinary
-			saved, saveErr := saveAttachmentFile(fh, complaint.ID)
-			if saveErr != nil {
-				tx.Rollback()
-				return c.Status(500).JSON(fiber.Map{"error": "Failed to save attachment", "details": saveErr.Error()})
-			}
-			// Upload to Cloudinary (fallback to local if not configured)
-			var att models.Attachment
-			if cld, cldErr := helpers.InitCloudinary(); cldErr == nil {
-				if upRes, upErr := cld.UploadJPEG(saved.Path, "complaints/"+complaint.ID.String(), uuid.New().String()); upErr == nil {
-					att = models.Attachment{
-						ID:          uuid.New(),
-						ComplaintID: complaint.ID,
-						FileName:    fh.Filename,
-						FileURL:     upRes.SecureURL,
-						PublicID:    upRes.PublicID,
-						Size:        fmt.Sprintf("%d", upRes.Bytes),
-						FilePath:    saved.Path,
-					}
-					// Best-effort: remove local temp file after successful upload
-					_ = os.Remove(saved.Path)
-				} else {
-					// Fallback: keep local, but provide web-accessible URL
-					att = models.Attachment{
-						ID:          uuid.New(),
-						ComplaintID: complaint.ID,
-						FileName:    fh.Filename,
-						FileURL:     saved.PublicURL,
-						PublicID:    "",
-						Size:        fmt.Sprintf("%d", saved.Size),
-						FilePath:    saved.Path,
-					}
-				}
-			} else {
-				// Fallback: keep local, but provide web-accessible URL
-				att = models.Attachment{
-					ID:          uuid.New(),
-					ComplaintID: complaint.ID,
-					FileName:    fh.Filename,
-					FileURL:     saved.PublicURL,
-					PublicID:    "",
-					Size:        fmt.Sprintf("%d", saved.Size),
-					FilePath:    saved.Path,
-				}
-			}
+	for _, fh := range files {
+		if fh == nil {
+			continue
+		}
+		att, saveErr := uploadComplaintAttachment(fh, complaint.ID)
+		if saveErr != nil {
+			tx.Rollback()
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to save attachment", "details": saveErr.Error()})
+		}
 
-			if err := tx.Create(&att).Error; err != nil {
-				tx.Rollback()
-				return c.Status(500).JSON(fiber.Map{"error": "Failed to create attachment record"})
-			}
+		if err := tx.Create(&att).Error; err != nil {
+			tx.Rollback()
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to create attachment record"})
 		}
 	}
 
-	tx.Commit()
-	// spawn notification creator after commit — notify admins (block wardens + chiefs)
-	go func(comp models.Complaint) {
-		var sm models.StudentModel
-		if err := config.DB.Where("user_id = ?", comp.UserID).First(&sm).Error; err != nil {
-			return
-		}
-		block := sm.Block
-		var admins []models.User
-		if block != "" {
-			config.DB.Where("role = ? AND block = ?", models.Admin, block).Find(&admins)
-		}
-		var chiefs []models.User
-		config.DB.Where("role = ?", models.ChiefAdmin).Find(&chiefs)
-		// deduplicate by ID
-		m := map[string]models.User{}
-		for _, a := range admins {
-			m[a.ID.String()] = a
-		}
-		for _, c := range chiefs {
-			m[c.ID.String()] = c
-		}
-		admins = []models.User{}
-		for _, v := range m {
-			admins = append(admins, v)
-		}
+	// notify admins (block wardens + chiefs) together with the complaint, never without it
+	related := complaint.ID
+	if err := jobs.EnqueueNotification(tx, models.NotificationFanout{
+		Block:       sm.Block,
+		Chiefs:      true,
+		Title:       "New Complaint Submitted",
+		Message:     "A student has submitted a complaint: " + complaint.Title,
+		Type:        "info",
+		RelatedID:   &related,
+		RelatedType: "complaint",
+	}); err != nil {
+		tx.Rollback()
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create complaint", "details": err.Error()})
+	}
 
-		related := comp.ID
-		rtype := "complaint"
-		for _, a := range admins {
-			n := models.Notification{
-				ID:          uuid.New(),
-				UserID:      a.ID,
-				Title:       "New Complaint Submitted",
-				Message:     "A student has submitted a complaint: " + comp.Title,
-				Type:        "info",
-				RelatedID:   &related,
-				RelatedType: &rtype,
-			}
-			config.DB.Create(&n)
-		}
-	}(complaint)
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create complaint", "details": err.Error()})
+	}
+	jobs.WakeOutbox()
 
 	return c.JSON(fiber.Map{"message": "Complaint submitted successfully", "id": complaint.ID})
 }
 
+// uploadComplaintAttachment saves the file locally, then pushes it to Cloudinary when
+// configured. The returned record is not persisted; callers create it in their transaction.
+func uploadComplaintAttachment(fh *multipart.FileHeader, complaintID uuid.UUID) (models.Attachment, error) {
+	// Save temporarily to disk then upload to Cloudinary
+	saved, err := saveAttachmentFile(fh, complaintID)
+	if err != nil {
+		return models.Attachment{}, err
+	}
+	// Fallback: keep local, but provide web-accessible URL
+	att := models.Attachment{
+		ID:          uuid.New(),
+		ComplaintID: complaintID,
+		FileName:    filepath.Base(saved.Path),
+		FileURL:     saved.PublicURL,
+		PublicID:    "",
+		Size:        fmt.Sprintf("%d", saved.Size),
+		FilePath:    saved.Path,
+	}
+	// Upload to Cloudinary (fallback to local if not configured)
+	if cld, cldErr := helpers.InitCloudinary(); cldErr == nil {
+		if upRes, upErr := cld.UploadJPEG(saved.Path, "complaints/"+complaintID.String(), uuid.New().String()); upErr == nil {
+			att.FileURL = upRes.SecureURL
+			att.PublicID = upRes.PublicID
+			att.Size = fmt.Sprintf("%d", upRes.Bytes)
+			// Best-effort: remove local temp file after successful upload
+			_ = os.Remove(saved.Path)
+		}
+	}
+	return att, nil
+}
+
 type savedFileInfo struct {
 	Path      string
 	PublicURL string
@@ -194,7 +197,9 @@ func saveAttachmentFile(fh *multipart.FileHeader, complaintID uuid.UUID) (*saved
 	}
 	defer src.Close()
 
-	dstPath := filepath.Join(dir, fh.Filename)
+	// never trust the client's filename for the path
+	name := sanitizeFilename(fh.Filename, "image/jpeg")
+	dstPath := filepath.Join(dir, name)
 	dst, err := os.Create(dstPath)
 	if err != nil {
 		return nil, err
@@ -207,25 +212,10 @@ func saveAttachmentFile(fh *multipart.FileHeader, complaintID uuid.UUID) (*saved
 	}
 	// Convert local FS path to a URL path served by app.Static("/uploads", "./uploads")
 	// Example: ./uploads/attachments/<complaintID>/<filename> -> /uploads/attachments/<complaintID>/<filename>
-	relURL := filepath.ToSlash(filepath.Join("/uploads/attachments", complaintID.String(), fh.Filename))
+	relURL := filepath.ToSlash(filepath.Join("/uploads/attachments", complaintID.String(), name))
 	return &savedFileInfo{Path: dstPath, PublicURL: relURL, Size: written}, nil
 }
 
-func isJPEG(fh *multipart.FileHeader) bool {
-	f, err := fh.Open()
-	if err != nil {
-		return false
-	}
-	defer f.Close()
-	buf := make([]byte, 512)
-	n, _ := f.Read(buf)
-	if n == 0 {
-		return false
-	}
-	ct := http.DetectContentType(buf[:n])
-	return ct == "image/jpeg" || ct == "image/jpg"
-}
-
 // 🧾 STUDENT + ADMIN — Get All Complaints
 func GetAllComplaints(c *fiber.Ctx) error {
 	role, _ := c.Locals("role").(string)
@@ -238,8 +228,14 @@ func GetAllComplaints(c *fiber.Ctx) error {
 			return db.Preload("User")
 		}).
 		Preload("Attachments").
-		Preload("Timeline").
+		Preload("Feedback").
 		Order("created_at DESC")
+	// Students never see internal staff notes
+	if role == string(models.Student) {
+		query = query.Preload("Timeline", publicTimeline)
+	} else {
+		query = query.Preload("Timeline")
+	}
 
 	err := query.Find(&complaints).Error
 	if err != nil {
@@ -263,6 +259,7 @@ func GetAllComplaints(c *fiber.Ctx) error {
 	if len(complaints) == 0 {
 		return c.JSON(fiber.Map{"message": "No complaints found", "data": []models.Complaint{}})
 	}
+	redactComplaintsForStaff(c, complaints)
 	// Ensure attachments are at least empty arrays for frontend rendering
 	for i := range complaints {
 		if complaints[i].Attachments == nil {
@@ -283,65 +280,205 @@ func UpdateComplaintStatus(c *fiber.Ctx) error {
 	}
 
 	var complaint models.Complaint
-	if err := config.DB.First(&complaint, "id = ?", id).Error; err != nil {
+	if err := config.DB.Preload("Student").First(&complaint, "id = ?", id).Error; err != nil {
 		return c.Status(404).JSON(fiber.Map{"error": "Complaint not found"})
 	}
+	if !canAccessStudentBlock(c, complaint.Student.Block) {
+		return c.Status(403).JSON(fiber.Map{"error": "Forbidden: admin not authorized for this complaint"})
+	}
+	version, ok := ifMatchVersion(c, complaint.Version)
+	if !ok {
+		return preconditionRequired(c)
+	}
+	if version != complaint.Version {
+		return preconditionFailed(c, complaint.Version)
+	}
+	if err := checkStatusTransition(complaint.Status, models.ComplaintStatus(input.Status)); err != nil {
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	}
 
 	tx := config.DB.Begin()
+	previousStatus := complaint.Status
 	complaint.Status = models.ComplaintStatus(input.Status)
 
-	if err := tx.Save(&complaint).Error; err != nil {
+	updated, err := updateIfVersion(tx, &models.Complaint{}, complaint.ID, version, map[string]interface{}{"status": complaint.Status})
+	if err != nil {
 		tx.Rollback()
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to update status"})
 	}
+	if !updated {
+		tx.Rollback()
+		var current models.Complaint
+		config.DB.Select("version").First(&current, "id = ?", complaint.ID)
+		return preconditionFailed(c, current.Version)
+	}
+	complaint.Version = version + 1
+	setETag(c, complaint.Version)
 
-	timeline := models.TimelineEntry{
-		ComplaintID: complaint.ID,
-		Author:      "Warden/Admin",
-		Message:     fmt.Sprintf("Status changed to %s", input.Status),
-		Timestamp:   time.Now(),
-	}
-	tx.Create(&timeline)
-	tx.Commit()
-
-	// Create student notification synchronously and return it in response to avoid race in tests
-	// Determine notification content
-	var title, message, ntype string
-	switch input.Status {
-	case "inprogress":
-		title = "Complaint In Progress"
-		message = "Your complaint is now being reviewed by the warden."
-		ntype = "info"
-	case "resolved":
-		title = "Complaint Resolved"
-		message = "Your complaint has been resolved. Please check for updates."
-		ntype = "success"
-	default:
-		// other statuses: don't notify
-		return c.JSON(fiber.Map{"message": "Status updated"})
+	role, _ := c.Locals("role").(string)
+	requesterID, _ := c.Locals("user_id").(string)
+	adminID, _ := uuid.Parse(requesterID)
+	timeline := newChangeEntry(complaint.ID, adminID, role, models.EventStatusChange, string(previousStatus), input.Status, fmt.Sprintf("Status changed to %s", input.Status))
+	if err := tx.Create(&timeline).Error; err != nil {
+		tx.Rollback()
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to add timeline entry"})
+	}
+	if err := enqueueWatcherNotification(tx, complaint.ID, "Followed Complaint Updated",
+		fmt.Sprintf("%q is now %s", complaint.Title, input.Status), "info", adminID, complaint.UserID); err != nil {
+		tx.Rollback()
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update status"})
+	}
+	if err := enqueueStatusNotification(tx, &complaint); err != nil {
+		tx.Rollback()
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update status"})
 	}
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update status", "details": err.Error()})
+	}
+	jobs.WakeOutbox()
 
-	related := complaint.ID
-	rtype := "complaint"
-	n := models.Notification{
-		ID:          uuid.New(),
-		UserID:      complaint.UserID,
+	return c.JSON(fiber.Map{"message": "Status updated"})
+}
+
+// checkStatusTransition validates an admin status change against the complaint state machine.
+func checkStatusTransition(from, to models.ComplaintStatus) error {
+	if !to.IsValid() {
+		return fmt.Errorf("unknown status %q", to)
+	}
+	if to == models.Withdrawn {
+		return errors.New("only the student can withdraw a complaint")
+	}
+	if !from.CanTransitionTo(to) {
+		return fmt.Errorf("cannot change status from %s to %s", from, to)
+	}
+	return nil
+}
+
+// statusNotification returns the student-facing notification for a status change, if any.
+func statusNotification(status models.ComplaintStatus) (title, message, ntype string, ok bool) {
+	switch status {
+	case models.InProgress:
+		return "Complaint In Progress", "Your complaint is now being reviewed by the warden.", "info", true
+	case models.Resolved:
+		return "Complaint Resolved", "Your complaint has been resolved. Please check for updates.", "success", true
+	}
+	return "", "", "", false
+}
+
+// enqueueStatusNotification queues the filing student's notice for the complaint's new status
+// inside tx, so it is delivered exactly when the status change commits.
+func enqueueStatusNotification(tx *gorm.DB, complaint *models.Complaint) error {
+	title, message, ntype, ok := statusNotification(complaint.Status)
+	if !ok {
+		return nil
+	}
+	return jobs.EnqueueNotification(tx, models.NotificationFanout{
+		UserIDs:     []uuid.UUID{complaint.UserID},
 		Title:       title,
 		Message:     message,
 		Type:        ntype,
-		RelatedID:   &related,
-		RelatedType: &rtype,
+		RelatedID:   &complaint.ID,
+		RelatedType: "complaint",
+	})
+}
+
+// enqueueAssignmentNotification queues the notice telling staff they were assigned a complaint.
+func enqueueAssignmentNotification(tx *gorm.DB, complaint *models.Complaint, assigneeID uuid.UUID) error {
+	return jobs.EnqueueNotification(tx, models.NotificationFanout{
+		UserIDs:     []uuid.UUID{assigneeID},
+		Title:       "Complaint Assigned",
+		Message:     "You have been assigned a complaint: " + complaint.Title,
+		Type:        "info",
+		RelatedID:   &complaint.ID,
+		RelatedType: "complaint",
+	})
+}
+
+// 🧑‍💼 ADMIN — Assign Complaint to a staff member
+func AssignComplaint(c *fiber.Ctx) error {
+	var input struct {
+		AssigneeID string `json:"assignee_id"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
+	}
+	assigneeUUID, err := uuid.Parse(input.AssigneeID)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "assignee_id must be a valid user id"})
 	}
 
-	if err := config.DB.Create(&n).Error; err != nil {
-		// Log and still return success to admin, but report notification failure
-		return c.Status(500).JSON(fiber.Map{"error": "Status updated but failed to create notification", "details": err.Error()})
+	var complaint models.Complaint
+	if err := config.DB.Preload("Student").First(&complaint, "id = ?", c.Params("id")).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Complaint not found"})
+	}
+	if !canAccessStudentBlock(c, complaint.Student.Block) {
+		return c.Status(403).JSON(fiber.Map{"error": "Forbidden: admin not authorized for this complaint"})
+	}
+	version, ok := ifMatchVersion(c, complaint.Version)
+	if !ok {
+		return preconditionRequired(c)
+	}
+	if version != complaint.Version {
+		return preconditionFailed(c, complaint.Version)
+	}
+	if complaint.Status == models.Resolved || complaint.Status == models.Withdrawn {
+		return c.Status(409).JSON(fiber.Map{"error": fmt.Sprintf("cannot assign a complaint that is %s", complaint.Status)})
+	}
+
+	var assignee models.User
+	if err := config.DB.First(&assignee, "id = ?", assigneeUUID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Assignee not found"})
+	}
+	if assignee.Role != models.Admin && assignee.Role != models.ChiefAdmin {
+		return c.Status(400).JSON(fiber.Map{"error": "Complaints can only be assigned to staff"})
+	}
+
+	previous := ""
+	if complaint.AssignedToID != nil {
+		previous = complaint.AssignedToID.String()
+	}
+	role, _ := c.Locals("role").(string)
+	requesterID, _ := c.Locals("user_id").(string)
+	adminID, _ := uuid.Parse(requesterID)
+
+	tx := config.DB.Begin()
+	// the status guard keeps a complaint resolved meanwhile from being assigned
+	updated, err := updateIfVersion(tx.Where("status = ?", complaint.Status), &models.Complaint{}, complaint.ID, version,
+		map[string]interface{}{"assigned_to_id": assigneeUUID})
+	if err != nil {
+		tx.Rollback()
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to assign complaint"})
 	}
+	if !updated {
+		tx.Rollback()
+		var current models.Complaint
+		config.DB.Select("version").First(&current, "id = ?", complaint.ID)
+		return preconditionFailed(c, current.Version)
+	}
+	entry := newChangeEntry(complaint.ID, adminID, role, models.EventAssignment, previous, assigneeUUID.String(), "Complaint assigned to "+assignee.Name)
+	if err := tx.Create(&entry).Error; err != nil {
+		tx.Rollback()
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to add timeline entry"})
+	}
+	// assigned staff follow the complaint from now on
+	if err := addWatcher(tx, complaint.ID, assignee.ID, models.WatchAssigned); err != nil {
+		tx.Rollback()
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to add assignee as follower"})
+	}
+	if err := enqueueAssignmentNotification(tx, &complaint, assignee.ID); err != nil {
+		tx.Rollback()
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to assign complaint"})
+	}
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to assign complaint", "details": err.Error()})
+	}
+	jobs.WakeOutbox()
+	setETag(c, version+1)
 
-	return c.JSON(fiber.Map{"message": "Status updated", "notification": n})
+	return c.JSON(fiber.Map{"message": "Complaint assigned", "assigned_to_id": assigneeUUID})
 }
 
-// 🧑‍💼 ADMIN — Delete Complaint
+// 🧑‍💼 ADMIN — Delete Complaint (moves it to the trash)
 func DeleteComplaint(c *fiber.Ctx) error {
 	id := c.Params("id")
 
@@ -351,9 +488,9 @@ func DeleteComplaint(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid complaint id"})
 	}
 
-	// Load complaint with related entities (for cleanup and existence check)
+	// Load complaint with the student (for block authorization and existence check)
 	var complaint models.Complaint
-	if err := config.DB.Preload("Attachments").Preload("Timeline").Preload("Student").First(&complaint, "id = ?", compUUID).Error; err != nil {
+	if err := config.DB.Preload("Student").First(&complaint, "id = ?", compUUID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return c.Status(404).JSON(fiber.Map{"error": "Complaint not found"})
 		}
@@ -376,54 +513,31 @@ func DeleteComplaint(c *fiber.Ctx) error {
 		}
 	}
 
+	// Soft delete: the complaint moves to the trash and keeps its attachments, timeline and
+	// notifications until jobs.PurgeExpiredTrash hard-deletes it after the retention window.
+	deleterID, _ := uuid.Parse(requesterID)
 	tx := config.DB.Begin()
-	// 1) Delete child records explicitly to satisfy FK constraints
-	if err := tx.Where("complaint_id = ?", compUUID).Delete(&models.Attachment{}).Error; err != nil {
+	if err := tx.Model(&complaint).Update("deleted_by_id", deleterID).Error; err != nil {
 		tx.Rollback()
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete attachments", "details": err.Error()})
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete complaint", "details": err.Error()})
 	}
-	if err := tx.Where("complaint_id = ?", compUUID).Delete(&models.TimelineEntry{}).Error; err != nil {
+	if err := tx.Delete(&complaint).Error; err != nil {
 		tx.Rollback()
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete timeline entries", "details": err.Error()})
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete complaint", "details": err.Error()})
 	}
-	// Notifications are not FK-constrained, but clean them up if they reference this complaint
-	rtype := "complaint"
-	if err := tx.Where("related_id = ? AND related_type = ?", compUUID, rtype).Delete(&models.Notification{}).Error; err != nil {
+	entry := newTimelineEntry(complaint.ID, deleterID, role, models.TimelineInternal, "Complaint moved to trash")
+	if err := tx.Create(&entry).Error; err != nil {
 		tx.Rollback()
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete related notifications", "details": err.Error()})
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to add timeline entry", "details": err.Error()})
 	}
-
-	// 2) Delete the complaint itself
-	if err := tx.Where("id = ?", compUUID).Delete(&models.Complaint{}).Error; err != nil {
-		tx.Rollback()
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete complaint", "details": err.Error()})
-	}
-
-	// 3) Commit DB changes before attempting filesystem cleanup
 	if err := tx.Commit().Error; err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to commit deletion", "details": err.Error()})
 	}
 
-	// 4) Best-effort: remove attachments from Cloudinary
-	if len(complaint.Attachments) > 0 {
-		if cld, cldErr := helpers.InitCloudinary(); cldErr == nil {
-			for _, a := range complaint.Attachments {
-				if a.PublicID != "" {
-					_ = cld.Destroy(a.PublicID)
-				}
-			}
-		}
-	}
-
-	// 5) Best-effort: remove attachments directory from disk
-	// Directory pattern: ./uploads/attachments/<complaintID>
-	attachDir := filepath.Join("./uploads/attachments", compUUID.String())
-	if stat, statErr := os.Stat(attachDir); statErr == nil && stat.IsDir() {
-		// Remove all files under this complaint's dir
-		_ = os.RemoveAll(attachDir)
-	}
-
-	return c.JSON(fiber.Map{"message": "Complaint deleted successfully"})
+	return c.JSON(fiber.Map{
+		"message":          "Complaint moved to trash",
+		"restorable_until": jobs.TrashConfigFromEnv().RestorableUntil(time.Now()),
+	})
 }
 
 // 📊 Filter Complaints by Type (Optional)
@@ -455,6 +569,7 @@ func GetComplaintbyID(c *fiber.Ctx) error {
 		}).
 		Preload("Timeline").
 		Preload("Attachments").
+		Preload("Feedback").
 		First(&complaint, "id = ?", complaintID).Error
 
 	if err != nil {
@@ -464,22 +579,32 @@ func GetComplaintbyID(c *fiber.Ctx) error {
 		})
 	}
 
+	if !canAccessStudentBlock(c, complaint.Student.Block) {
+		return c.Status(403).JSON(fiber.Map{"error": "Forbidden: admin not authorized for this complaint"})
+	}
 	if complaint.Attachments == nil {
 		complaint.Attachments = make([]models.Attachment, 0)
 	}
 
-	// Fetch user's past complaints (excluding this one)
+	// Fetch user's past complaints (excluding this one); never for confidential complaints,
+	// since the history would identify the student
 	var pastComplaints []models.Complaint
-	config.DB.Where("user_id = ? AND id != ?", complaint.UserID, complaint.ID).
-		Find(&pastComplaints)
+	if !complaint.IsConfidential {
+		// the student's own confidential complaints stay out of the history too
+		config.DB.Where("user_id = ? AND id != ? AND is_confidential = false", complaint.UserID, complaint.ID).
+			Find(&pastComplaints)
+	}
+	redactComplaint(&complaint)
 
 	response := fiber.Map{
-		"id":          complaint.ID,
-		"title":       complaint.Title,
-		"type":        complaint.Type,
-		"description": complaint.Description,
-		"status":      complaint.Status,
-		"created_at":  complaint.CreatedAt,
+		"id":           complaint.ID,
+		"title":        complaint.Title,
+		"type":         complaint.Type,
+		"description":  complaint.Description,
+		"status":       complaint.Status,
+		"version":      complaint.Version,
+		"created_at":   complaint.CreatedAt,
+		"confidential": complaint.IsConfidential,
 		"user": fiber.Map{
 			"id":      complaint.User.ID,
 			"name":    complaint.User.Name,
@@ -487,11 +612,13 @@ func GetComplaintbyID(c *fiber.Ctx) error {
 			"block":   complaint.Student.Block,
 			"room_no": complaint.Student.RoomNo,
 		},
+		"feedback":        complaint.Feedback,
 		"attachments":     complaint.Attachments,
 		"timeline":        complaint.Timeline,
 		"past_complaints": pastComplaints,
 	}
 
+	setETag(c, complaint.Version)
 	return c.JSON(response)
 }
 
@@ -500,34 +627,20 @@ func GetComplaintbyID(c *fiber.Ctx) error {
 func GetAllComplaintsAdmin(c *fiber.Ctx) error {
 	var complaints []models.Complaint
 
-	role, _ := c.Locals("role").(string)
-	userID, _ := c.Locals("user_id").(string)
-
-	query := config.DB.Preload("User").Preload("Student").Preload("Attachments").Preload("Timeline")
+	query := config.DB.Preload("User").Preload("Student").Preload("Attachments").Preload("Timeline").Preload("Feedback").Preload("Tags")
 
-	// Optional filters
-	if status := c.Query("status"); status != "" {
-		query = query.Where("status = ?", status)
-	}
-	if complaintType := c.Query("type"); complaintType != "" {
-		query = query.Where("type = ?", complaintType)
+	// Optional filters (status, type, priority, block, assigned_to, tags, from, to) plus block scoping for admins.
+	// ?view=<saved view id> starts from a saved view; explicit query params override it.
+	filter, sort, errResp := complaintListFilter(c)
+	if filter == nil {
+		return errResp
 	}
+	query = query.Scopes(filter.scope(c))
 
-	// If the requester is an admin (not chief_admin), restrict to their block (A-Z)
-	if role == string(models.Admin) && userID != "" {
-		var reqUser models.User
-		if err := config.DB.First(&reqUser, "id = ?", userID).Error; err == nil {
-			if reqUser.Block != "" {
-				// Only show complaints for students in the same block
-				query = query.Joins("LEFT JOIN student_models ON student_models.user_id = complaints.user_id").
-					Where("student_models.block = ?", reqUser.Block)
-			}
-		}
-	}
-
-	if err := query.Order("created_at desc").Find(&complaints).Error; err != nil {
+	if err := query.Order(complaintOrder(sort)).Find(&complaints).Error; err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch complaints"})
 	}
+	redactComplaintsForStaff(c, complaints)
 	// Normalize nil attachments to empty arrays for frontend clients
 	for i := range complaints {
 		if complaints[i].Attachments == nil {
@@ -0,0 +1,318 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aditisaxena259/mental-health-be/config"
+	"github.com/aditisaxena259/mental-health-be/jobs"
+	"github.com/aditisaxena259/mental-health-be/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// maxBulkComplaints caps how many complaints one bulk request may touch.
+const maxBulkComplaints = 500
+
+type bulkChanges struct {
+	Status     *string  `json:"status"`
+	Priority   *string  `json:"priority"`
+	AssigneeID *string  `json:"assignee_id"`
+	AddTags    []string `json:"add_tags"`
+	RemoveTags []string `json:"remove_tags"`
+	// Comment is posted to each complaint's public timeline
+	Comment string `json:"comment"`
+}
+
+func (ch bulkChanges) empty() bool {
+	return ch.Status == nil && ch.Priority == nil && ch.AssigneeID == nil &&
+		len(ch.AddTags) == 0 && len(ch.RemoveTags) == 0 && strings.TrimSpace(ch.Comment) == ""
+}
+
+type bulkItemResult struct {
+	ID      string   `json:"id"`
+	OK      bool     `json:"ok"`
+	Error   string   `json:"error,omitempty"`
+	Changed []string `json:"changed,omitempty"`
+}
+
+// complaintActor identifies who is making a change, for timeline entries.
+type complaintActor struct {
+	ID   uuid.UUID
+	Role string
+}
+
+// errComplaintChanged is reported for a bulk item whose complaint was updated by someone
+// else between loading it and applying the change set.
+var errComplaintChanged = errors.New("complaint changed meanwhile")
+
+// applyComplaintChanges applies one bulk change set to a single complaint inside tx and
+// records the matching timeline events. It returns the names of the fields that changed.
+// Field changes are written only while the complaint still has the status and version it
+// was loaded with; otherwise errComplaintChanged is returned.
+func applyComplaintChanges(tx *gorm.DB, comp *models.Complaint, ch bulkChanges, assignee *models.User, actor complaintActor) ([]string, error) {
+	changed := []string{}
+	updates := map[string]interface{}{}
+	entries := []models.TimelineEntry{}
+
+	if ch.Status != nil && models.ComplaintStatus(*ch.Status) != comp.Status {
+		next := models.ComplaintStatus(*ch.Status)
+		if err := checkStatusTransition(comp.Status, next); err != nil {
+			return nil, err
+		}
+		updates["status"] = next
+		entries = append(entries, newChangeEntry(comp.ID, actor.ID, actor.Role, models.EventStatusChange, string(comp.Status), string(next), fmt.Sprintf("Status changed to %s", next)))
+		changed = append(changed, "status")
+	}
+
+	if ch.Priority != nil && models.ComplaintPriority(*ch.Priority) != comp.Priority {
+		updates["priority"] = *ch.Priority
+		entries = append(entries, newChangeEntry(comp.ID, actor.ID, actor.Role, models.EventPriorityChange, string(comp.Priority), *ch.Priority, fmt.Sprintf("Priority changed to %s", *ch.Priority)))
+		changed = append(changed, "priority")
+	}
+
+	if assignee != nil && (comp.AssignedToID == nil || *comp.AssignedToID != assignee.ID) {
+		previous := ""
+		if comp.AssignedToID != nil {
+			previous = comp.AssignedToID.String()
+		}
+		updates["assigned_to_id"] = assignee.ID
+		entries = append(entries, newChangeEntry(comp.ID, actor.ID, actor.Role, models.EventAssignment, previous, assignee.ID.String(), "Complaint assigned to "+assignee.Name))
+		changed = append(changed, "assignee")
+	}
+
+	if len(updates) > 0 {
+		ok, err := updateIfVersion(tx.Where("status = ?", comp.Status), &models.Complaint{}, comp.ID, comp.Version, updates)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, errComplaintChanged
+		}
+		comp.Version++
+		if next, ok := updates["status"].(models.ComplaintStatus); ok {
+			comp.Status = next
+		}
+		if _, ok := updates["priority"]; ok {
+			comp.Priority = models.ComplaintPriority(*ch.Priority)
+		}
+		if _, ok := updates["assigned_to_id"]; ok {
+			comp.AssignedToID = &assignee.ID
+			if err := addWatcher(tx, comp.ID, assignee.ID, models.WatchAssigned); err != nil {
+				return nil, err
+			}
+		}
+		if err := tx.Create(&entries).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	if len(ch.AddTags) > 0 || len(ch.RemoveTags) > 0 {
+		tagChanged, err := applyTagChanges(tx, comp, ch.AddTags, ch.RemoveTags, actor)
+		if err != nil {
+			return nil, err
+		}
+		if tagChanged {
+			changed = append(changed, "tags")
+		}
+	}
+
+	if comment := strings.TrimSpace(ch.Comment); comment != "" {
+		entry := newTimelineEntry(comp.ID, actor.ID, actor.Role, models.TimelinePublic, comment)
+		if err := tx.Create(&entry).Error; err != nil {
+			return nil, err
+		}
+		changed = append(changed, "comment")
+	}
+	return changed, nil
+}
+
+// applyTagChanges adds and removes block-scoped tags on a complaint (Tags must be preloaded)
+// and leaves an internal timeline note. It reports whether anything changed.
+func applyTagChanges(tx *gorm.DB, comp *models.Complaint, add, remove []string, actor complaintActor) (bool, error) {
+	has := map[string]bool{}
+	for _, t := range comp.Tags {
+		has[t.Name] = true
+	}
+	added, removed := []string{}, []string{}
+	for _, name := range add {
+		if has[normalizeTagName(name)] {
+			continue
+		}
+		tag, err := findOrCreateTag(tx, comp.Student.Block, name, actor.ID)
+		if err != nil {
+			return false, err
+		}
+		if err := tx.Model(comp).Association("Tags").Append(tag); err != nil {
+			return false, err
+		}
+		has[tag.Name] = true
+		added = append(added, tag.Name)
+	}
+	for _, name := range remove {
+		name = normalizeTagName(name)
+		if !has[name] {
+			continue
+		}
+		var tag models.Tag
+		if err := tx.Where("block = ? AND name = ?", comp.Student.Block, name).First(&tag).Error; err != nil {
+			continue
+		}
+		if err := tx.Model(comp).Association("Tags").Delete(&tag); err != nil {
+			return false, err
+		}
+		delete(has, name)
+		removed = append(removed, name)
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		return false, nil
+	}
+
+	parts := []string{}
+	if len(added) > 0 {
+		parts = append(parts, "added "+strings.Join(added, ", "))
+	}
+	if len(removed) > 0 {
+		parts = append(parts, "removed "+strings.Join(removed, ", "))
+	}
+	entry := newTimelineEntry(comp.ID, actor.ID, actor.Role, models.TimelineInternal, "Tags "+strings.Join(parts, "; "))
+	return true, tx.Create(&entry).Error
+}
+
+// 🧑‍💼 ADMIN — Bulk update complaints selected by ids or by a filter
+// Each complaint is authorized, validated and committed on its own; per-item results are returned.
+// Student and assignee notifications are queued with each complaint's own transaction.
+func BulkUpdateComplaints(c *fiber.Ctx) error {
+	var input struct {
+		IDs     []string         `json:"ids"`
+		Filter  *complaintFilter `json:"filter"`
+		Changes bulkChanges      `json:"changes"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
+	}
+	if (len(input.IDs) == 0) == (input.Filter == nil) {
+		return c.Status(400).JSON(fiber.Map{"error": "Provide either ids or filter"})
+	}
+	if input.Changes.empty() {
+		return c.Status(400).JSON(fiber.Map{"error": "No changes provided"})
+	}
+	if len(input.IDs) > maxBulkComplaints {
+		return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("At most %d complaints per request", maxBulkComplaints)})
+	}
+
+	// Validate the change set once before touching any complaint
+	ch := input.Changes
+	if ch.Status != nil {
+		if s := models.ComplaintStatus(*ch.Status); !s.IsValid() || s == models.Withdrawn {
+			return c.Status(400).JSON(fiber.Map{"error": "status must be one of open, inprogress, resolved"})
+		}
+	}
+	if ch.Priority != nil && !isValidPriority(models.ComplaintPriority(*ch.Priority)) {
+		return c.Status(400).JSON(fiber.Map{"error": "priority must be one of low, medium, high"})
+	}
+	var assignee *models.User
+	if ch.AssigneeID != nil {
+		var u models.User
+		if err := config.DB.First(&u, "id = ?", *ch.AssigneeID).Error; err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Assignee not found"})
+		}
+		if u.Role != models.Admin && u.Role != models.ChiefAdmin {
+			return c.Status(400).JSON(fiber.Map{"error": "Complaints can only be assigned to staff"})
+		}
+		assignee = &u
+	}
+
+	role, _ := c.Locals("role").(string)
+	requesterID, _ := c.Locals("user_id").(string)
+	actorID, _ := uuid.Parse(requesterID)
+	actor := complaintActor{ID: actorID, Role: role}
+	adminBlock := requesterAdminBlock(c)
+
+	// Resolve the target complaints
+	results := []bulkItemResult{}
+	var complaints []models.Complaint
+	query := config.DB.Preload("Student").Preload("Tags")
+	if input.Filter != nil {
+		if err := query.Scopes(input.Filter.scope(c)).Order("created_at asc").Limit(maxBulkComplaints + 1).Find(&complaints).Error; err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch complaints"})
+		}
+		if len(complaints) > maxBulkComplaints {
+			return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("Filter matches more than %d complaints; narrow it down", maxBulkComplaints)})
+		}
+	} else {
+		ids := []uuid.UUID{}
+		for _, raw := range input.IDs {
+			id, err := uuid.Parse(raw)
+			if err != nil {
+				results = append(results, bulkItemResult{ID: raw, Error: "invalid complaint id"})
+				continue
+			}
+			ids = append(ids, id)
+		}
+		if len(ids) > 0 {
+			if err := query.Where("id IN ?", ids).Find(&complaints).Error; err != nil {
+				return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch complaints"})
+			}
+		}
+		found := map[uuid.UUID]bool{}
+		for _, comp := range complaints {
+			found[comp.ID] = true
+		}
+		for _, id := range ids {
+			if !found[id] {
+				results = append(results, bulkItemResult{ID: id.String(), Error: "complaint not found"})
+			}
+		}
+	}
+
+	// Apply per complaint; notifications are queued in the same transaction as each change
+	for i := range complaints {
+		comp := &complaints[i]
+		if role != string(models.ChiefAdmin) && (adminBlock == "" || comp.Student.Block != adminBlock) {
+			results = append(results, bulkItemResult{ID: comp.ID.String(), Error: "forbidden: complaint is outside your block"})
+			continue
+		}
+		previousStatus := comp.Status
+		tx := config.DB.Begin()
+		changed, err := applyComplaintChanges(tx, comp, ch, assignee, actor)
+		if err == nil && comp.Status != previousStatus {
+			err = enqueueWatcherNotification(tx, comp.ID, "Followed Complaint Updated",
+				fmt.Sprintf("%q is now %s", comp.Title, comp.Status), "info", actor.ID, comp.UserID)
+			if err == nil {
+				err = enqueueStatusNotification(tx, comp)
+			}
+		}
+		if err == nil && assignee != nil && slices.Contains(changed, "assignee") {
+			err = enqueueAssignmentNotification(tx, comp, assignee.ID)
+		}
+		if err != nil {
+			tx.Rollback()
+			results = append(results, bulkItemResult{ID: comp.ID.String(), Error: err.Error()})
+			continue
+		}
+		if err := tx.Commit().Error; err != nil {
+			results = append(results, bulkItemResult{ID: comp.ID.String(), Error: "failed to commit: " + err.Error()})
+			continue
+		}
+		results = append(results, bulkItemResult{ID: comp.ID.String(), OK: true, Changed: changed})
+	}
+	jobs.WakeOutbox()
+
+	succeeded := 0
+	for _, r := range results {
+		if r.OK {
+			succeeded++
+		}
+	}
+	return c.JSON(fiber.Map{
+		"message":   "Bulk update processed",
+		"total":     len(results),
+		"succeeded": succeeded,
+		"failed":    len(results) - succeeded,
+		"results":   results,
+	})
+}
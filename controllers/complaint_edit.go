@@ -0,0 +1,282 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aditisaxena259/mental-health-be/config"
+	"github.com/aditisaxena259/mental-health-be/jobs"
+	"github.com/aditisaxena259/mental-health-be/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// loadOwnComplaint fetches a complaint by the :id param that belongs to the logged-in student.
+func loadOwnComplaint(c *fiber.Ctx) (*models.Complaint, error) {
+	userID, _ := c.Locals("user_id").(string)
+	compUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, c.Status(400).JSON(fiber.Map{"error": "Invalid complaint id"})
+	}
+	var complaint models.Complaint
+	if err := config.DB.First(&complaint, "id = ? AND user_id = ?", compUUID, userID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, c.Status(404).JSON(fiber.Map{"error": "Complaint not found"})
+		}
+		return nil, c.Status(500).JSON(fiber.Map{"error": "Failed to load complaint", "details": err.Error()})
+	}
+	return &complaint, nil
+}
+
+func isValidPriority(p models.ComplaintPriority) bool {
+	switch p {
+	case models.PriorityLow, models.PriorityMedium, models.PriorityHigh:
+		return true
+	}
+	return false
+}
+
+// ✏️ STUDENT — Edit own complaint while it is still open
+func UpdateOwnComplaint(c *fiber.Ctx) error {
+	var input struct {
+		Title       *string `json:"title" form:"title"`
+		Description *string `json:"description" form:"description"`
+		Priority    *string `json:"priority" form:"priority"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
+	}
+
+	complaint, errResp := loadOwnComplaint(c)
+	if complaint == nil {
+		return errResp
+	}
+	if complaint.Status != models.Open {
+		return c.Status(409).JSON(fiber.Map{"error": "Only open complaints can be edited"})
+	}
+
+	updates := map[string]interface{}{}
+	changed := []string{}
+	if input.Title != nil && strings.TrimSpace(*input.Title) != complaint.Title {
+		if strings.TrimSpace(*input.Title) == "" {
+			return c.Status(400).JSON(fiber.Map{"error": "title cannot be empty"})
+		}
+		updates["title"] = strings.TrimSpace(*input.Title)
+		changed = append(changed, "title")
+	}
+	if input.Description != nil && strings.TrimSpace(*input.Description) != complaint.Description {
+		if strings.TrimSpace(*input.Description) == "" {
+			return c.Status(400).JSON(fiber.Map{"error": "description cannot be empty"})
+		}
+		updates["description"] = strings.TrimSpace(*input.Description)
+		changed = append(changed, "description")
+	}
+	if input.Priority != nil && models.ComplaintPriority(*input.Priority) != complaint.Priority {
+		if !isValidPriority(models.ComplaintPriority(*input.Priority)) {
+			return c.Status(400).JSON(fiber.Map{"error": "priority must be one of low, medium, high"})
+		}
+		updates["priority"] = *input.Priority
+		changed = append(changed, "priority")
+	}
+	if len(changed) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "No changes provided"})
+	}
+
+	tx := config.DB.Begin()
+	// the guarded update locks the complaint row, so concurrent edits serialize here and
+	// the loser sees a stale version instead of racing for the same revision number
+	updated, err := updateIfVersion(tx.Where("status = ?", models.Open), &models.Complaint{}, complaint.ID, complaint.Version, updates)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update complaint", "details": err.Error()})
+	}
+	if !updated {
+		tx.Rollback()
+		return c.Status(409).JSON(fiber.Map{"error": "Complaint was updated meanwhile; reload and retry"})
+	}
+	var lastRevision int
+	if err := tx.Model(&models.ComplaintRevision{}).Where("complaint_id = ?", complaint.ID).
+		Select("COALESCE(MAX(revision), 0)").Scan(&lastRevision).Error; err != nil {
+		tx.Rollback()
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to record revision", "details": err.Error()})
+	}
+	rev := models.ComplaintRevision{
+		ID:            uuid.New(),
+		ComplaintID:   complaint.ID,
+		EditorID:      complaint.UserID,
+		Revision:      lastRevision + 1,
+		Title:         complaint.Title,
+		Description:   complaint.Description,
+		Priority:      complaint.Priority,
+		ChangedFields: strings.Join(changed, ","),
+	}
+	if err := tx.Create(&rev).Error; err != nil {
+		tx.Rollback()
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to record revision", "details": err.Error()})
+	}
+	entries := []models.TimelineEntry{
+		newChangeEntry(complaint.ID, complaint.UserID, string(models.Student), models.EventEdited, "", strings.Join(changed, ","), fmt.Sprintf("Complaint edited by student (%s)", strings.Join(changed, ", "))),
+	}
+	if newPriority, ok := updates["priority"].(string); ok {
+		entries = append(entries, newChangeEntry(complaint.ID, complaint.UserID, string(models.Student), models.EventPriorityChange, string(rev.Priority), newPriority, fmt.Sprintf("Priority changed to %s", newPriority)))
+	}
+	if err := tx.Create(&entries).Error; err != nil {
+		tx.Rollback()
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to add timeline entry"})
+	}
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to save changes", "details": err.Error()})
+	}
+
+	config.DB.First(complaint, "id = ?", complaint.ID)
+	return c.JSON(fiber.Map{"message": "Complaint updated", "data": complaint, "revision": rev.Revision})
+}
+
+// 📎 STUDENT — Add attachments to own open complaint
+func AddComplaintAttachments(c *fiber.Ctx) error {
+	complaint, errResp := loadOwnComplaint(c)
+	if complaint == nil {
+		return errResp
+	}
+	if complaint.Status != models.Open {
+		return c.Status(409).JSON(fiber.Map{"error": "Attachments can only be added to open complaints"})
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil || form == nil || len(form.File["attachments"]) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "At least one file is required under 'attachments'"})
+	}
+
+	if _, err := checkAttachments(form.File["attachments"], complaintAttachmentTypes); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	tx := config.DB.Begin()
+	added := []models.Attachment{}
+	for _, fh := range form.File["attachments"] {
+		if fh == nil {
+			continue
+		}
+		att, saveErr := uploadComplaintAttachment(fh, complaint.ID)
+		if saveErr != nil {
+			tx.Rollback()
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to save attachment", "details": saveErr.Error()})
+		}
+		if err := tx.Create(&att).Error; err != nil {
+			tx.Rollback()
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to create attachment record"})
+		}
+		added = append(added, att)
+	}
+	entry := newChangeEntry(complaint.ID, complaint.UserID, string(models.Student), models.EventAttachmentAdded, "", fmt.Sprintf("%d", len(added)), fmt.Sprintf("Student added %d attachment(s)", len(added)))
+	if err := tx.Create(&entry).Error; err != nil {
+		tx.Rollback()
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to add timeline entry"})
+	}
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to save attachments", "details": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "Attachments added", "data": added})
+}
+
+// ↩️ STUDENT — Withdraw own complaint before it is resolved
+func WithdrawComplaint(c *fiber.Ctx) error {
+	var input struct {
+		Reason string `json:"reason" form:"reason"`
+	}
+	_ = c.BodyParser(&input)
+
+	complaint, errResp := loadOwnComplaint(c)
+	if complaint == nil {
+		return errResp
+	}
+	if complaint.Status == models.Resolved || complaint.Status == models.Withdrawn {
+		return c.Status(409).JSON(fiber.Map{"error": fmt.Sprintf("Complaint is already %s", complaint.Status)})
+	}
+
+	message := "Complaint withdrawn by student"
+	if r := strings.TrimSpace(input.Reason); r != "" {
+		message += ": " + r
+	}
+
+	previousStatus := complaint.Status
+	tx := config.DB.Begin()
+	updated, err := updateIfVersion(tx.Where("status IN ?", []models.ComplaintStatus{models.Open, models.InProgress}),
+		&models.Complaint{}, complaint.ID, complaint.Version, map[string]interface{}{"status": models.Withdrawn})
+	if err != nil {
+		tx.Rollback()
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to withdraw complaint"})
+	}
+	if !updated {
+		tx.Rollback()
+		return c.Status(409).JSON(fiber.Map{"error": "Complaint was updated meanwhile; reload and retry"})
+	}
+	complaint.Status = models.Withdrawn
+	entry := newChangeEntry(complaint.ID, complaint.UserID, string(models.Student), models.EventStatusChange, string(previousStatus), string(models.Withdrawn), message)
+	if err := tx.Create(&entry).Error; err != nil {
+		tx.Rollback()
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to add timeline entry"})
+	}
+	// let the block wardens know the complaint no longer needs attention
+	var sm models.StudentModel
+	tx.Where("user_id = ?", complaint.UserID).First(&sm)
+	if err := jobs.EnqueueNotification(tx, models.NotificationFanout{
+		Block:       sm.Block,
+		Chiefs:      true,
+		Title:       "Complaint Withdrawn",
+		Message:     "A student has withdrawn their complaint: " + complaint.Title,
+		Type:        "info",
+		RelatedID:   &complaint.ID,
+		RelatedType: "complaint",
+	}); err != nil {
+		tx.Rollback()
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to withdraw complaint"})
+	}
+	if err := enqueueWatcherNotification(tx, complaint.ID, "Followed Complaint Withdrawn",
+		fmt.Sprintf("%q was withdrawn by the student", complaint.Title), "info", complaint.UserID); err != nil {
+		tx.Rollback()
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to withdraw complaint"})
+	}
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to withdraw complaint", "details": err.Error()})
+	}
+	jobs.WakeOutbox()
+
+	return c.JSON(fiber.Map{"message": "Complaint withdrawn", "data": complaint})
+}
+
+// 🧑‍💼 ADMIN — Revision history of a complaint
+func GetComplaintRevisions(c *fiber.Ctx) error {
+	compUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid complaint id"})
+	}
+	var complaint models.Complaint
+	if err := config.DB.Preload("Student").First(&complaint, "id = ?", compUUID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Complaint not found"})
+	}
+	if !canAccessStudentBlock(c, complaint.Student.Block) {
+		return c.Status(403).JSON(fiber.Map{"error": "Forbidden: admin not authorized for this complaint"})
+	}
+
+	var revisions []models.ComplaintRevision
+	if err := config.DB.Where("complaint_id = ?", compUUID).Order("revision asc").Find(&revisions).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load revisions"})
+	}
+	if complaint.IsConfidential {
+		for i := range revisions {
+			revisions[i].EditorID = uuid.Nil
+		}
+	}
+	return c.JSON(fiber.Map{
+		"count": len(revisions),
+		"current": fiber.Map{
+			"title":       complaint.Title,
+			"description": complaint.Description,
+			"priority":    complaint.Priority,
+		},
+		"data": revisions,
+	})
+}
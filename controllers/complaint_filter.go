@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"strings"
+	"time"
+
+	"github.com/aditisaxena259/mental-health-be/models"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// complaintFilter is the set of filters shared by the admin complaint list, saved views,
+// bulk updates and exports. Block admins are always restricted to their own block on top of it.
+type complaintFilter models.ComplaintFilter
+
+func complaintFilterFromQuery(c *fiber.Ctx) complaintFilter {
+	f := complaintFilter{
+		Status:     c.Query("status"),
+		Type:       c.Query("type"),
+		Priority:   c.Query("priority"),
+		Block:      c.Query("block"),
+		AssignedTo: c.Query("assigned_to"),
+		From:       c.Query("from"),
+		To:         c.Query("to"),
+	}
+	if tags := c.Query("tags"); tags != "" {
+		f.Tags = strings.Split(tags, ",")
+	}
+	return f
+}
+
+// merge overlays the non-empty fields of override onto f.
+func (f complaintFilter) merge(override complaintFilter) complaintFilter {
+	if override.Status != "" {
+		f.Status = override.Status
+	}
+	if override.Type != "" {
+		f.Type = override.Type
+	}
+	if override.Priority != "" {
+		f.Priority = override.Priority
+	}
+	if override.Block != "" {
+		f.Block = override.Block
+	}
+	if override.AssignedTo != "" {
+		f.AssignedTo = override.AssignedTo
+	}
+	if len(override.Tags) > 0 {
+		f.Tags = override.Tags
+	}
+	if override.From != "" {
+		f.From = override.From
+	}
+	if override.To != "" {
+		f.To = override.To
+	}
+	return f
+}
+
+// scope applies the filter plus the requester's block restriction to a complaints query.
+// Tags match when the complaint carries every listed tag.
+func (f complaintFilter) scope(c *fiber.Ctx) func(db *gorm.DB) *gorm.DB {
+	adminBlock := requesterAdminBlock(c)
+	return func(db *gorm.DB) *gorm.DB {
+		if f.Status != "" {
+			db = db.Where("complaints.status = ?", f.Status)
+		}
+		if f.Type != "" {
+			db = db.Where("complaints.type = ?", f.Type)
+		}
+		if f.Priority != "" {
+			db = db.Where("complaints.priority = ?", f.Priority)
+		}
+		if f.AssignedTo == "none" {
+			db = db.Where("complaints.assigned_to_id IS NULL")
+		} else if f.AssignedTo != "" {
+			db = db.Where("complaints.assigned_to_id = ?", f.AssignedTo)
+		}
+		if f.Block != "" {
+			db = db.Where("complaints.user_id IN (SELECT user_id FROM student_models WHERE block = ?)", strings.ToUpper(f.Block))
+		}
+		if from, err := time.Parse("2006-01-02", f.From); err == nil {
+			db = db.Where("complaints.created_at >= ?", from)
+		}
+		if to, err := time.Parse("2006-01-02", f.To); err == nil {
+			db = db.Where("complaints.created_at < ?", to.AddDate(0, 0, 1))
+		}
+		for _, tag := range f.Tags {
+			if name := normalizeTagName(tag); name != "" {
+				db = db.Where(`complaints.id IN (SELECT complaint_tags.complaint_id FROM complaint_tags
+					JOIN tags ON tags.id = complaint_tags.tag_id WHERE tags.name = ?)`, name)
+			}
+		}
+		// If the requester is an admin (not chief_admin), restrict to their block (A-Z)
+		if adminBlock != "" {
+			db = db.Where("complaints.user_id IN (SELECT user_id FROM student_models WHERE block = ?)", adminBlock)
+		} else if role, _ := c.Locals("role").(string); role == string(models.Admin) {
+			// an admin whose block cannot be resolved sees nothing
+			db = db.Where("1 = 0")
+		}
+		return db
+	}
+}
+
+// complaintListFilter resolves the filter and sort of a complaint list request: query params,
+// optionally on top of the saved view named by ?view=.
+func complaintListFilter(c *fiber.Ctx) (*complaintFilter, string, error) {
+	filter := complaintFilterFromQuery(c)
+	sort := c.Query("sort")
+	if viewID := c.Query("view"); viewID != "" {
+		view, errResp := loadVisibleView(c, viewID)
+		if view == nil {
+			return nil, "", errResp
+		}
+		filter = complaintFilter(view.Filter).merge(filter)
+		if sort == "" {
+			sort = view.Sort
+		}
+	}
+	return &filter, sort, nil
+}
+
+// complaintSorts maps the accepted ?sort= values to ORDER BY clauses. A leading "-" sorts descending.
+var complaintSorts = map[string]string{
+	"created_at":  "complaints.created_at asc",
+	"-created_at": "complaints.created_at desc",
+	"due_at":      "complaints.due_at asc nulls last",
+	"-due_at":     "complaints.due_at desc nulls last",
+	"priority":    "CASE complaints.priority WHEN 'high' THEN 0 WHEN 'medium' THEN 1 ELSE 2 END asc, complaints.created_at desc",
+	"-priority":   "CASE complaints.priority WHEN 'high' THEN 0 WHEN 'medium' THEN 1 ELSE 2 END desc, complaints.created_at desc",
+	"status":      "complaints.status asc, complaints.created_at desc",
+	"-impact":     "complaints.impact_score desc, complaints.created_at desc",
+}
+
+// complaintOrder returns the ORDER BY clause for sort, defaulting to newest first.
+func complaintOrder(sort string) string {
+	if order, ok := complaintSorts[sort]; ok {
+		return order
+	}
+	return complaintSorts["-created_at"]
+}
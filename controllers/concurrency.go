@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Complaints and apologies carry a version that every update increments. Reads expose it as
+// an ETag; status changes and reviews must echo it back in If-Match so that two wardens
+// working on the same record cannot silently overwrite each other.
+
+// etag formats a record version as a strong ETag.
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// setETag sets the ETag response header for a record at version.
+func setETag(c *fiber.Ctx, version int) {
+	c.Set(fiber.HeaderETag, etag(version))
+}
+
+// ifMatchVersion parses the If-Match header against the record's current version.
+// "*" matches whatever is current. ok is false when the header is missing or malformed.
+func ifMatchVersion(c *fiber.Ctx, current int) (version int, ok bool) {
+	header := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if header == "" {
+		return 0, false
+	}
+	if header == "*" {
+		return current, true
+	}
+	header = strings.Trim(strings.TrimPrefix(header, "W/"), `"`)
+	v, err := strconv.Atoi(header)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// bumpVersion adds the version increment to an update map so outstanding ETags go stale.
+func bumpVersion(updates map[string]interface{}) map[string]interface{} {
+	updates["version"] = gorm.Expr("version + 1")
+	return updates
+}
+
+// updateIfVersion applies updates to the row identified by id only while it is still at
+// version. It reports false when a concurrent update got there first.
+func updateIfVersion(tx *gorm.DB, model interface{}, id interface{}, version int, updates map[string]interface{}) (bool, error) {
+	res := tx.Model(model).Where("id = ? AND version = ?", id, version).Updates(bumpVersion(updates))
+	return res.RowsAffected == 1, res.Error
+}
+
+// preconditionRequired is the 428 response for updates sent without If-Match.
+func preconditionRequired(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusPreconditionRequired).JSON(fiber.Map{"error": "If-Match header with the record's ETag is required"})
+}
+
+// preconditionFailed is the 412 response when the record changed since the client read it.
+func preconditionFailed(c *fiber.Ctx, current int) error {
+	setETag(c, current)
+	return c.Status(fiber.StatusPreconditionFailed).JSON(fiber.Map{
+		"error":           "Record was modified by someone else; reload it and retry",
+		"current_version": current,
+	})
+}
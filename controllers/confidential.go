@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"strings"
+
+	"github.com/aditisaxena259/mental-health-be/config"
+	"github.com/aditisaxena259/mental-health-be/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// redactComplaint strips the filing student's identity and room from a confidential
+// complaint. The block is kept so scoping and routing still work.
+func redactComplaint(comp *models.Complaint) {
+	if !comp.IsConfidential {
+		return
+	}
+	comp.UserID = uuid.Nil
+	comp.StudentIdentifier = ""
+	comp.User = models.User{}
+	comp.Student = models.StudentModel{Block: comp.Student.Block}
+	// the room (and any fixture in it) would point straight at the student
+	comp.RoomID, comp.Room = nil, nil
+	comp.AssetID, comp.Asset = nil, nil
+	if comp.Feedback != nil {
+		comp.Feedback.StudentID = uuid.Nil
+	}
+	redactTimeline(comp.Timeline)
+}
+
+// redactTimeline hides which student authored entries on a confidential complaint.
+func redactTimeline(entries []models.TimelineEntry) {
+	for i := range entries {
+		if entries[i].Author == string(models.Student) {
+			entries[i].AuthorID = nil
+		}
+	}
+}
+
+// redactComplaintsForStaff applies redactComplaint unless the requester is the filing student.
+func redactComplaintsForStaff(c *fiber.Ctx, complaints []models.Complaint) {
+	if role, _ := c.Locals("role").(string); role == string(models.Student) {
+		return
+	}
+	for i := range complaints {
+		redactComplaint(&complaints[i])
+	}
+}
+
+// 🕵️ CHIEF ADMIN / COUNSELOR — Reveal who filed a confidential complaint (audited)
+func RevealConfidentialComplaint(c *fiber.Ctx) error {
+	var input struct {
+		Reason string `json:"reason"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
+	}
+	if strings.TrimSpace(input.Reason) == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "A reason is required to reveal a confidential complaint"})
+	}
+
+	compUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid complaint id"})
+	}
+	var complaint models.Complaint
+	if err := config.DB.Preload("User").Preload("Student").First(&complaint, "id = ?", compUUID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(404).JSON(fiber.Map{"error": "Complaint not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load complaint", "details": err.Error()})
+	}
+	if !complaint.IsConfidential {
+		return c.Status(400).JSON(fiber.Map{"error": "Complaint is not confidential"})
+	}
+
+	role, _ := c.Locals("role").(string)
+	userID, _ := c.Locals("user_id").(string)
+	viewerID, err := uuid.Parse(userID)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized: missing user ID"})
+	}
+	if role != string(models.ChiefAdmin) {
+		var viewer models.User
+		if err := config.DB.Select("can_reveal_confidential").First(&viewer, "id = ?", viewerID).Error; err != nil || !viewer.CanRevealConfidential {
+			return c.Status(403).JSON(fiber.Map{"error": "Forbidden: not designated to reveal confidential complaints"})
+		}
+	}
+
+	// The audit record must be written before any identity leaves the server
+	entry := models.ConfidentialAccessLog{
+		ID:          uuid.New(),
+		ComplaintID: complaint.ID,
+		ViewerID:    viewerID,
+		ViewerRole:  models.RoleType(role),
+		Reason:      strings.TrimSpace(input.Reason),
+		IPAddress:   c.IP(),
+	}
+	if err := config.DB.Create(&entry).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to record access; identity not revealed"})
+	}
+
+	return c.JSON(fiber.Map{
+		"complaint_id": complaint.ID,
+		"student": fiber.Map{
+			"user_id":    complaint.UserID,
+			"name":       complaint.User.Name,
+			"email":      complaint.User.Email,
+			"student_id": complaint.StudentIdentifier,
+			"block":      complaint.Student.Block,
+			"room_no":    complaint.Student.RoomNo,
+		},
+	})
+}
+
+// 🕵️ CHIEF ADMIN — Grant or revoke a counselor's permission to reveal confidential complaints
+func SetConfidentialRevealer(c *fiber.Ctx) error {
+	var input struct {
+		Allowed *bool `json:"allowed"`
+	}
+	if err := c.BodyParser(&input); err != nil || input.Allowed == nil {
+		return c.Status(400).JSON(fiber.Map{"error": "allowed (true or false) is required"})
+	}
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", c.Params("id")).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "User not found"})
+	}
+	if user.Role != models.Counselor {
+		return c.Status(400).JSON(fiber.Map{"error": "Only counselors can be designated to reveal confidential complaints"})
+	}
+	if err := config.DB.Model(&user).Update("can_reveal_confidential", *input.Allowed).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update counselor"})
+	}
+	return c.JSON(fiber.Map{
+		"message": "Confidential reveal permission updated",
+		"data": fiber.Map{
+			"id":                      user.ID,
+			"name":                    user.Name,
+			"can_reveal_confidential": *input.Allowed,
+		},
+	})
+}
+
+// 🧾 CHIEF ADMIN — Audit trail of identity reveals for a complaint
+func GetConfidentialAccessLog(c *fiber.Ctx) error {
+	var logs []models.ConfidentialAccessLog
+	if err := config.DB.Where("complaint_id = ?", c.Params("id")).Order("created_at desc").Find(&logs).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load access log"})
+	}
+	return c.JSON(fiber.Map{"count": len(logs), "data": logs})
+}
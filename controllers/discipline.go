@@ -0,0 +1,243 @@
+package controllers
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aditisaxena259/mental-health-be/config"
+	"github.com/aditisaxena259/mental-health-be/jobs"
+	"github.com/aditisaxena259/mental-health-be/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// disciplineConfig holds the strike thresholds and lifetime.
+// Env: DISCIPLINE_WARNING_POINTS (default 3), DISCIPLINE_ESCALATION_POINTS (default 6),
+// DISCIPLINE_STRIKE_DAYS (default 180).
+type disciplineConfig struct {
+	WarningPoints    int `json:"warning_points"`
+	EscalationPoints int `json:"escalation_points"`
+	StrikeDays       int `json:"strike_days"`
+}
+
+func disciplineConfigFromEnv() disciplineConfig {
+	cfg := disciplineConfig{WarningPoints: 3, EscalationPoints: 6, StrikeDays: 180}
+	if v, err := strconv.Atoi(os.Getenv("DISCIPLINE_WARNING_POINTS")); err == nil && v > 0 {
+		cfg.WarningPoints = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("DISCIPLINE_ESCALATION_POINTS")); err == nil && v > 0 {
+		cfg.EscalationPoints = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("DISCIPLINE_STRIKE_DAYS")); err == nil && v > 0 {
+		cfg.StrikeDays = v
+	}
+	return cfg
+}
+
+// level names the threshold a point total has reached.
+func (cfg disciplineConfig) level(points int) string {
+	switch {
+	case points >= cfg.EscalationPoints:
+		return "escalated"
+	case points >= cfg.WarningPoints:
+		return "warning"
+	}
+	return "clear"
+}
+
+// activeStrikePoints sums the unexpired strikes of a student whose apologies are not in the trash.
+func activeStrikePoints(db *gorm.DB, studentID uuid.UUID) int {
+	var points int
+	db.Model(&models.DisciplinaryStrike{}).
+		Joins("JOIN apologies ON apologies.id = disciplinary_strikes.apology_id AND apologies.deleted_at IS NULL").
+		Where("disciplinary_strikes.student_id = ? AND disciplinary_strikes.expires_at > ?", studentID, time.Now()).
+		Select("COALESCE(SUM(disciplinary_strikes.severity), 0)").
+		Scan(&points)
+	return points
+}
+
+// recordStrike derives the strike for a decided apology inside tx, replacing any earlier strike
+// for the same apology (e.g. after an appeal). It returns the active points before and after.
+func recordStrike(tx *gorm.DB, apology models.Apology, outcome models.ApologyStatus) (int, int, error) {
+	before := activeStrikePoints(tx, apology.StudentID)
+	severity := models.StrikeSeverity(apology.ApologyType, outcome)
+	if severity == 0 {
+		if err := tx.Where("apology_id = ?", apology.ID).Delete(&models.DisciplinaryStrike{}).Error; err != nil {
+			return before, before, err
+		}
+		return before, activeStrikePoints(tx, apology.StudentID), nil
+	}
+	cfg := disciplineConfigFromEnv()
+	strike := models.DisciplinaryStrike{
+		ID:        uuid.New(),
+		StudentID: apology.StudentID,
+		ApologyID: apology.ID,
+		Type:      apology.ApologyType,
+		Outcome:   outcome,
+		Severity:  severity,
+		ExpiresAt: apology.CreatedAt.AddDate(0, 0, cfg.StrikeDays),
+	}
+	err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "apology_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"outcome", "severity", "updated_at"}),
+	}).Create(&strike).Error
+	if err != nil {
+		return before, before, err
+	}
+	return before, activeStrikePoints(tx, apology.StudentID), nil
+}
+
+// notifyDisciplineThreshold queues alerts in tx when a student's active points cross a threshold
+// upwards: the student and block wardens on a warning, the chief admins on escalation.
+func notifyDisciplineThreshold(tx *gorm.DB, studentID uuid.UUID, before, after int) error {
+	cfg := disciplineConfigFromEnv()
+	if cfg.level(after) == cfg.level(before) || after < before {
+		return nil
+	}
+	var sm models.StudentModel
+	if err := tx.Preload("User").Where("user_id = ?", studentID).First(&sm).Error; err != nil {
+		return err
+	}
+	related := studentID
+	staff := models.NotificationFanout{
+		Title:       "Disciplinary Warning",
+		Message:     fmt.Sprintf("%s (%s) has reached %d active strike points", sm.User.Name, sm.StudentIdentifier, after),
+		Type:        "warning",
+		RelatedID:   &related,
+		RelatedType: "discipline",
+	}
+	if cfg.level(after) == "escalated" {
+		staff.Chiefs = true
+		staff.Title = "Disciplinary Escalation"
+		staff.Message = fmt.Sprintf("%s (%s, block %s) has reached %d active strike points", sm.User.Name, sm.StudentIdentifier, sm.Block, after)
+	} else {
+		staff.Block = sm.Block
+	}
+	if err := jobs.EnqueueNotification(tx, staff); err != nil {
+		return err
+	}
+	return jobs.EnqueueNotification(tx, models.NotificationFanout{
+		UserIDs:     []uuid.UUID{studentID},
+		Title:       "Disciplinary Warning",
+		Message:     fmt.Sprintf("You have %d active strike points on your disciplinary record. Further incidents may lead to escalation.", after),
+		Type:        "warning",
+		RelatedID:   &related,
+		RelatedType: "discipline",
+	})
+}
+
+// loadBlockStudent resolves :id as a student user id or student identifier, within the requester's block.
+func loadBlockStudent(c *fiber.Ctx) (*models.StudentModel, error) {
+	query := config.DB.Preload("User")
+	if id, err := uuid.Parse(c.Params("id")); err == nil {
+		query = query.Where("user_id = ?", id)
+	} else {
+		query = query.Where("student_identifier = ?", c.Params("id"))
+	}
+	var sm models.StudentModel
+	if err := query.First(&sm).Error; err != nil {
+		return nil, c.Status(404).JSON(fiber.Map{"error": "Student not found"})
+	}
+	if !canAccessStudentBlock(c, sm.Block) {
+		return nil, c.Status(403).JSON(fiber.Map{"error": "Forbidden: not authorized for this student"})
+	}
+	return &sm, nil
+}
+
+// 🧑‍💼 ADMIN — A student's disciplinary history: strikes, active points and apology record
+func GetStudentDisciplinaryHistory(c *fiber.Ctx) error {
+	sm, errResp := loadBlockStudent(c)
+	if sm == nil {
+		return errResp
+	}
+	cfg := disciplineConfigFromEnv()
+
+	var strikes []models.DisciplinaryStrike
+	if err := config.DB.Joins("JOIN apologies ON apologies.id = disciplinary_strikes.apology_id AND apologies.deleted_at IS NULL").
+		Where("disciplinary_strikes.student_id = ?", sm.UserID).
+		Order("disciplinary_strikes.created_at desc").
+		Find(&strikes).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load strikes"})
+	}
+	type strikeView struct {
+		models.DisciplinaryStrike
+		Active bool `json:"active"`
+	}
+	now := time.Now()
+	views := make([]strikeView, 0, len(strikes))
+	for _, s := range strikes {
+		views = append(views, strikeView{DisciplinaryStrike: s, Active: s.ExpiresAt.After(now)})
+	}
+
+	var apologies []models.Apology
+	if err := config.DB.Preload("Appeal.History", appealHistoryOrder).
+		Where("student_id = ?", sm.UserID).
+		Order("created_at desc").
+		Find(&apologies).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load apologies"})
+	}
+
+	points := activeStrikePoints(config.DB, sm.UserID)
+	return c.JSON(fiber.Map{
+		"student": fiber.Map{
+			"id":                 sm.UserID,
+			"name":               sm.User.Name,
+			"student_identifier": sm.StudentIdentifier,
+			"block":              sm.Block,
+			"room_no":            sm.RoomNo,
+		},
+		"active_points": points,
+		"level":         cfg.level(points),
+		"thresholds":    cfg,
+		"strikes":       views,
+		"apologies":     apologies,
+	})
+}
+
+// 🧑‍💼 ADMIN — Students with active strike points, highest first (?level=warning|escalated)
+func GetDisciplineOverview(c *fiber.Ctx) error {
+	cfg := disciplineConfigFromEnv()
+	var rows []struct {
+		StudentID         uuid.UUID `json:"student_id"`
+		Name              string    `json:"name"`
+		StudentIdentifier string    `json:"student_identifier"`
+		Block             string    `json:"block"`
+		ActivePoints      int       `json:"active_points"`
+		Strikes           int       `json:"strikes"`
+		Level             string    `json:"level" gorm:"-"`
+	}
+	query := config.DB.Table("disciplinary_strikes").
+		Select(`disciplinary_strikes.student_id, users.name, student_models.student_identifier, student_models.block,
+			SUM(disciplinary_strikes.severity) AS active_points, COUNT(*) AS strikes`).
+		Joins("JOIN apologies ON apologies.id = disciplinary_strikes.apology_id AND apologies.deleted_at IS NULL").
+		Joins("JOIN student_models ON student_models.user_id = disciplinary_strikes.student_id").
+		Joins("JOIN users ON users.id = disciplinary_strikes.student_id").
+		Where("disciplinary_strikes.expires_at > ?", time.Now()).
+		Group("disciplinary_strikes.student_id, users.name, student_models.student_identifier, student_models.block").
+		Order("active_points desc")
+	if block := requesterAdminBlock(c); block != "" {
+		query = query.Where("student_models.block = ?", block)
+	} else if role, _ := c.Locals("role").(string); role == string(models.Admin) {
+		query = query.Where("1 = 0")
+	} else if block := c.Query("block"); block != "" {
+		query = query.Where("student_models.block = ?", strings.ToUpper(block))
+	}
+	switch c.Query("level") {
+	case "warning":
+		query = query.Having("SUM(disciplinary_strikes.severity) >= ?", cfg.WarningPoints)
+	case "escalated":
+		query = query.Having("SUM(disciplinary_strikes.severity) >= ?", cfg.EscalationPoints)
+	}
+	if err := query.Scan(&rows).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load disciplinary overview"})
+	}
+	for i := range rows {
+		rows[i].Level = cfg.level(rows[i].ActivePoints)
+	}
+	return c.JSON(fiber.Map{"count": len(rows), "thresholds": cfg, "data": rows})
+}
@@ -0,0 +1,245 @@
+package controllers
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aditisaxena259/mental-health-be/config"
+	"github.com/aditisaxena259/mental-health-be/helpers"
+	"github.com/aditisaxena259/mental-health-be/models"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// exportColumn is one selectable export column: a header and a SQL expression yielding text.
+type exportColumn struct {
+	Header string
+	Expr   string
+}
+
+// Identity columns of confidential complaints are blanked in SQL, exactly like the list endpoints redact them.
+var complaintExportColumns = map[string]exportColumn{
+	"id":                 {"ID", "complaints.id::text"},
+	"title":              {"Title", "complaints.title"},
+	"type":               {"Type", "complaints.type"},
+	"category":           {"Category", "(SELECT complaint_categories.name FROM complaint_categories WHERE complaint_categories.id = complaints.category_id)"},
+	"status":             {"Status", "complaints.status::text"},
+	"priority":           {"Priority", "complaints.priority"},
+	"block":              {"Block", "student_models.block"},
+	"room":               {"Room", "CASE WHEN complaints.is_confidential THEN NULL ELSE rooms.number END"},
+	"student_name":       {"Student", "CASE WHEN complaints.is_confidential THEN 'Confidential' ELSE users.name END"},
+	"student_identifier": {"Student ID", "CASE WHEN complaints.is_confidential THEN NULL ELSE complaints.student_identifier END"},
+	"assignee":           {"Assignee", "assignees.name"},
+	"impact_score":       {"Impact", "complaints.impact_score::text"},
+	"tags": {"Tags", `(SELECT string_agg(tags.name, '; ' ORDER BY tags.name) FROM complaint_tags
+		JOIN tags ON tags.id = complaint_tags.tag_id WHERE complaint_tags.complaint_id = complaints.id)`},
+	"created_at": {"Created", "to_char(complaints.created_at, 'YYYY-MM-DD HH24:MI')"},
+	"due_at":     {"Due", "to_char(complaints.due_at, 'YYYY-MM-DD HH24:MI')"},
+	"resolved_at": {"Resolved", `(SELECT to_char(MAX(timeline_entries.timestamp), 'YYYY-MM-DD HH24:MI') FROM timeline_entries
+		WHERE timeline_entries.complaint_id = complaints.id AND timeline_entries.event_type = 'status_change' AND timeline_entries.new_value = 'resolved')`},
+	"rating":      {"Rating", "(SELECT complaint_feedback.rating::text FROM complaint_feedback WHERE complaint_feedback.complaint_id = complaints.id)"},
+	"description": {"Description", "complaints.description"},
+}
+
+var complaintExportDefault = []string{"id", "created_at", "block", "type", "title", "priority", "status", "assignee", "resolved_at"}
+
+var apologyExportColumns = map[string]exportColumn{
+	"id":                 {"ID", "apologies.id::text"},
+	"student_name":       {"Student", "users.name"},
+	"student_identifier": {"Student ID", "apologies.student_identifier"},
+	"block":              {"Block", "student_models.block"},
+	"room":               {"Room", "student_models.room_no"},
+	"type":               {"Type", "apologies.apology_type"},
+	"message":            {"Message", "apologies.message"},
+	"description":        {"Description", "apologies.description"},
+	"status":             {"Status", "apologies.status"},
+	"comment":            {"Review Comment", "apologies.comment"},
+	"created_at":         {"Submitted", "to_char(apologies.created_at, 'YYYY-MM-DD HH24:MI')"},
+}
+
+var apologyExportDefault = []string{"id", "created_at", "block", "student_identifier", "student_name", "type", "status", "comment"}
+
+// exportSelection resolves ?columns= (comma separated) against the available columns.
+func exportSelection(c *fiber.Ctx, available map[string]exportColumn, defaults []string) ([]exportColumn, error) {
+	keys := defaults
+	if raw := strings.TrimSpace(c.Query("columns")); raw != "" {
+		keys = strings.Split(raw, ",")
+	}
+	cols := make([]exportColumn, 0, len(keys))
+	for _, key := range keys {
+		col, ok := available[strings.TrimSpace(key)]
+		if !ok {
+			names := make([]string, 0, len(available))
+			for name := range available {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("unknown column %q; available: %s", key, strings.Join(names, ", "))
+		}
+		cols = append(cols, col)
+	}
+	return cols, nil
+}
+
+// selectColumns builds the SELECT list for cols, aliasing each expression positionally.
+func selectColumns(cols []exportColumn) string {
+	exprs := make([]string, len(cols))
+	for i, col := range cols {
+		exprs[i] = fmt.Sprintf("(%s)::text AS col_%d", col.Expr, i)
+	}
+	return strings.Join(exprs, ", ")
+}
+
+// tableWriter is the row sink shared by the CSV and XLSX exports.
+type tableWriter interface {
+	WriteRow(record []string) error
+	Close() error
+}
+
+type csvTableWriter struct{ w *csv.Writer }
+
+// WriteRow writes a record, neutralising cells a spreadsheet would evaluate as a formula.
+func (t csvTableWriter) WriteRow(record []string) error {
+	for i, v := range record {
+		if v != "" && strings.ContainsAny(v[:1], "=+-@") {
+			record[i] = "'" + v
+		}
+	}
+	return t.w.Write(record)
+}
+
+func (t csvTableWriter) Close() error {
+	t.w.Flush()
+	return t.w.Error()
+}
+
+// streamExport runs query and streams its rows as ?format=csv (default) or xlsx.
+// Rows are read from the cursor and flushed in chunks, so large exports never sit in memory.
+func streamExport(c *fiber.Ctx, name string, cols []exportColumn, query *gorm.DB) error {
+	format := strings.ToLower(c.Query("format", "csv"))
+	if format != "csv" && format != "xlsx" {
+		return c.Status(400).JSON(fiber.Map{"error": "format must be csv or xlsx"})
+	}
+
+	rows, err := query.Select(selectColumns(cols)).Rows()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to export " + name, "details": err.Error()})
+	}
+
+	filename := fmt.Sprintf("%s-%s.%s", name, time.Now().Format("20060102"), format)
+	if format == "xlsx" {
+		c.Set(fiber.HeaderContentType, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	} else {
+		c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	}
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	headers := make([]string, len(cols))
+	for i, col := range cols {
+		headers[i] = col.Header
+	}
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer rows.Close()
+		if err := writeExportRows(w, format, name, headers, rows); err != nil {
+			log.Printf("⚠️ %s export aborted: %v", name, err)
+		}
+		w.Flush()
+	})
+	return nil
+}
+
+func writeExportRows(w *bufio.Writer, format, sheet string, headers []string, rows *sql.Rows) error {
+	var out tableWriter
+	if format == "xlsx" {
+		x, err := helpers.NewXLSXWriter(w, sheet)
+		if err != nil {
+			return err
+		}
+		out = x
+	} else {
+		out = csvTableWriter{csv.NewWriter(w)}
+	}
+	if err := out.WriteRow(headers); err != nil {
+		return err
+	}
+
+	values := make([]sql.NullString, len(headers))
+	dest := make([]interface{}, len(headers))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for n := 1; rows.Next(); n++ {
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+		record := make([]string, len(values))
+		for i, v := range values {
+			record[i] = v.String
+		}
+		if err := out.WriteRow(record); err != nil {
+			return err
+		}
+		// push completed rows to the client regularly instead of buffering the whole file
+		if n%500 == 0 {
+			if err := flushExport(out, w); err != nil {
+				return err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+func flushExport(out tableWriter, w io.Writer) error {
+	if cw, ok := out.(csvTableWriter); ok {
+		cw.w.Flush()
+	}
+	if bw, ok := w.(*bufio.Writer); ok {
+		return bw.Flush()
+	}
+	return nil
+}
+
+// 📤 ADMIN — Export complaints as CSV/XLSX, with the same filters and block scoping as the list
+func ExportComplaints(c *fiber.Ctx) error {
+	cols, err := exportSelection(c, complaintExportColumns, complaintExportDefault)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	filter, sort, errResp := complaintListFilter(c)
+	if filter == nil {
+		return errResp
+	}
+	query := config.DB.Model(&models.Complaint{}).
+		Joins("LEFT JOIN users ON users.id = complaints.user_id").
+		Joins("LEFT JOIN student_models ON student_models.user_id = complaints.user_id").
+		Joins("LEFT JOIN rooms ON rooms.id = complaints.room_id").
+		Joins("LEFT JOIN users assignees ON assignees.id = complaints.assigned_to_id").
+		Scopes(filter.scope(c)).
+		Order(complaintOrder(sort))
+	return streamExport(c, "complaints", cols, query)
+}
+
+// 📤 ADMIN — Export apologies as CSV/XLSX, with the same filters and block scoping as the list
+func ExportApologies(c *fiber.Ctx) error {
+	cols, err := exportSelection(c, apologyExportColumns, apologyExportDefault)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	query := config.DB.Model(&models.Apology{}).
+		Joins("LEFT JOIN users ON users.id = apologies.student_id").
+		Joins("LEFT JOIN student_models ON student_models.user_id = apologies.student_id").
+		Scopes(apologyFilterScope(c)).
+		Order("apologies.created_at desc")
+	return streamExport(c, "apologies", cols, query)
+}
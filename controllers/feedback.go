@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/aditisaxena259/mental-health-be/config"
+	"github.com/aditisaxena259/mental-health-be/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// isUniqueViolation reports whether err is Postgres rejecting a duplicate key.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// ⭐ STUDENT — Rate a resolved complaint (once)
+func SubmitComplaintFeedback(c *fiber.Ctx) error {
+	var input struct {
+		Rating  int    `json:"rating"`
+		Comment string `json:"comment"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
+	}
+	if input.Rating < 1 || input.Rating > 5 {
+		return c.Status(400).JSON(fiber.Map{"error": "rating must be between 1 and 5"})
+	}
+
+	complaint, errResp := loadOwnComplaint(c)
+	if complaint == nil {
+		return errResp
+	}
+	if complaint.Status != models.Resolved {
+		return c.Status(409).JSON(fiber.Map{"error": "Feedback can only be given once the complaint is resolved"})
+	}
+
+	var existing int64
+	config.DB.Model(&models.ComplaintFeedback{}).Where("complaint_id = ?", complaint.ID).Count(&existing)
+	if existing > 0 {
+		return c.Status(409).JSON(fiber.Map{"error": "Feedback already submitted for this complaint"})
+	}
+
+	feedback := models.ComplaintFeedback{
+		ID:          uuid.New(),
+		ComplaintID: complaint.ID,
+		StudentID:   complaint.UserID,
+		Rating:      input.Rating,
+		Comment:     strings.TrimSpace(input.Comment),
+	}
+	if err := config.DB.Create(&feedback).Error; err != nil {
+		// unique index on complaint_id guards against a concurrent double submit
+		if isUniqueViolation(err) {
+			return c.Status(409).JSON(fiber.Map{"error": "Feedback already submitted for this complaint"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to save feedback", "details": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "Thank you for your feedback", "data": feedback})
+}
+
+// GET /admin/metrics/satisfaction?group_by=block|type|assignee|month
+// Average rating and count of ratings, scoped to the admin's block for plain admins.
+func GetSatisfactionMetrics(c *fiber.Ctx) error {
+	groupExprs := map[string]string{
+		"block":    "student_models.block",
+		"type":     "complaints.type::text",
+		"assignee": "COALESCE(users.name, 'Unassigned')",
+		"month":    "to_char(date_trunc('month', complaint_feedback.created_at), 'YYYY-MM')",
+	}
+	groupBy := c.Query("group_by", "month")
+	expr, ok := groupExprs[groupBy]
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"error": "group_by must be one of block, type, assignee, month"})
+	}
+
+	query := config.DB.Table("complaint_feedback").
+		Select(expr + " AS bucket, AVG(complaint_feedback.rating) AS avg_rating, COUNT(*) AS count").
+		Joins("JOIN complaints ON complaints.id = complaint_feedback.complaint_id AND complaints.deleted_at IS NULL").
+		Joins("LEFT JOIN student_models ON student_models.user_id = complaints.user_id").
+		Joins("LEFT JOIN users ON users.id = complaints.assigned_to_id").
+		Group("bucket").
+		Order("bucket")
+	if block := requesterAdminBlock(c); block != "" {
+		query = query.Where("student_models.block = ?", block)
+	} else if role, _ := c.Locals("role").(string); role == string(models.Admin) {
+		query = query.Where("1 = 0")
+	}
+
+	var rows []struct {
+		Bucket    string  `json:"bucket"`
+		AvgRating float64 `json:"avg_rating"`
+		Count     int64   `json:"count"`
+	}
+	if err := query.Scan(&rows).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to compute satisfaction metrics"})
+	}
+
+	var overall struct {
+		AvgRating *float64
+		Count     int64
+	}
+	for _, r := range rows {
+		overall.Count += r.Count
+	}
+	if overall.Count > 0 {
+		sum := 0.0
+		for _, r := range rows {
+			sum += r.AvgRating * float64(r.Count)
+		}
+		avg := sum / float64(overall.Count)
+		overall.AvgRating = &avg
+	}
+
+	return c.JSON(fiber.Map{
+		"group_by":   groupBy,
+		"avg_rating": overall.AvgRating,
+		"count":      overall.Count,
+		"data":       rows,
+	})
+}
@@ -0,0 +1,384 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aditisaxena259/mental-health-be/config"
+	"github.com/aditisaxena259/mental-health-be/helpers"
+	"github.com/aditisaxena259/mental-health-be/jobs"
+	"github.com/aditisaxena259/mental-health-be/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var errGuardianAckChanged = errors.New("guardian acknowledgement changed concurrently")
+
+var guardianPhonePattern = regexp.MustCompile(`^\+?[0-9]{7,15}$`)
+
+type guardianContactInput struct {
+	Name     *string `json:"guardian_name"`
+	Relation *string `json:"guardian_relation"`
+	Email    *string `json:"guardian_email"`
+	Phone    *string `json:"guardian_phone"`
+}
+
+// apply copies the provided guardian fields onto sm and validates them.
+func (in guardianContactInput) apply(sm *models.StudentModel) error {
+	if in.Name != nil {
+		sm.GuardianName = strings.TrimSpace(*in.Name)
+	}
+	if in.Relation != nil {
+		sm.GuardianRelation = strings.TrimSpace(*in.Relation)
+	}
+	if in.Email != nil {
+		sm.GuardianEmail = strings.TrimSpace(*in.Email)
+		if sm.GuardianEmail != "" {
+			addr, err := mail.ParseAddress(sm.GuardianEmail)
+			if err != nil || addr.Address != sm.GuardianEmail {
+				return errors.New("guardian_email must be a plain email address")
+			}
+		}
+	}
+	if in.Phone != nil {
+		sm.GuardianPhone = strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(*in.Phone))
+		if sm.GuardianPhone != "" && !guardianPhonePattern.MatchString(sm.GuardianPhone) {
+			return errors.New("guardian_phone must be 7 to 15 digits, optionally starting with +")
+		}
+	}
+	return nil
+}
+
+func guardianContact(sm models.StudentModel) fiber.Map {
+	return fiber.Map{
+		"student_id":        sm.UserID,
+		"guardian_name":     sm.GuardianName,
+		"guardian_relation": sm.GuardianRelation,
+		"guardian_email":    sm.GuardianEmail,
+		"guardian_phone":    sm.GuardianPhone,
+	}
+}
+
+// 🧑‍🎓 STUDENT — Own guardian contact (read only; the warden keeps it, since the guardian
+// must be someone the student cannot substitute)
+func GetMyGuardianContact(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	var sm models.StudentModel
+	if err := config.DB.Where("user_id = ?", userID).First(&sm).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Student profile not found"})
+	}
+	return c.JSON(guardianContact(sm))
+}
+
+// 🧑‍💼 ADMIN — A student's guardian contact
+func GetStudentGuardianContact(c *fiber.Ctx) error {
+	sm, errResp := loadBlockStudent(c)
+	if sm == nil {
+		return errResp
+	}
+	return c.JSON(guardianContact(*sm))
+}
+
+// 🧑‍💼 ADMIN — Update a student's guardian contact (only the fields sent are changed). Apologies
+// still awaiting their guardian get a fresh link sent to the new contact.
+func UpdateStudentGuardianContact(c *fiber.Ctx) error {
+	sm, errResp := loadBlockStudent(c)
+	if sm == nil {
+		return errResp
+	}
+	var input guardianContactInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
+	}
+	if err := input.apply(sm); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	cfg := jobs.GuardianConfigFromEnv()
+	refreshed := 0
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.StudentModel{}).Where("id = ?", sm.ID).Updates(map[string]interface{}{
+			"guardian_name":     sm.GuardianName,
+			"guardian_relation": sm.GuardianRelation,
+			"guardian_email":    sm.GuardianEmail,
+			"guardian_phone":    sm.GuardianPhone,
+		}).Error; err != nil {
+			return err
+		}
+		if !sm.HasGuardianContact() {
+			return nil
+		}
+		var acks []models.GuardianAcknowledgement
+		if err := tx.Where("student_id = ? AND status IN ?", sm.UserID,
+			[]models.GuardianAckStatus{models.GuardianAckPending, models.GuardianAckUnreachable}).
+			Where("apology_id IN (SELECT id FROM apologies WHERE status = ? AND deleted_at IS NULL)", models.ApologyAwaitingGuardian).
+			Find(&acks).Error; err != nil {
+			return err
+		}
+		for _, ack := range acks {
+			// a new nonce retires links sent to the old contact
+			if err := tx.Model(&models.GuardianAcknowledgement{}).Where("id = ?", ack.ID).Updates(map[string]interface{}{
+				"status":           models.GuardianAckPending,
+				"guardian_name":    sm.GuardianName,
+				"guardian_email":   sm.GuardianEmail,
+				"guardian_phone":   sm.GuardianPhone,
+				"link_nonce":       helpers.NewLinkNonce(),
+				"expires_at":       time.Now().Add(time.Duration(cfg.ExpiryHours) * time.Hour),
+				"sent_at":          nil,
+				"reminders_sent":   0,
+				"last_reminder_at": nil,
+			}).Error; err != nil {
+				return err
+			}
+			event := newApologyEvent(c, ack.ApologyID, models.ApologyEventGuardian, "", "", "Guardian contact updated; acknowledgement link re-sent")
+			if err := tx.Create(&event).Error; err != nil {
+				return err
+			}
+			refreshed++
+		}
+		return nil
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to save guardian contact"})
+	}
+	if refreshed > 0 {
+		jobs.WakeGuardianAckMonitor()
+	}
+	return c.JSON(fiber.Map{"message": "Guardian contact saved", "data": guardianContact(*sm), "links_resent": refreshed})
+}
+
+// newGuardianAck builds the acknowledgement request for a new apology, to be created in the
+// apology's transaction. The guardian monitor sends the link once it is committed. Without a
+// guardian contact it is recorded as unreachable for the warden to see.
+func newGuardianAck(cfg jobs.GuardianConfig, apology models.Apology, sm models.StudentModel) models.GuardianAcknowledgement {
+	ack := models.GuardianAcknowledgement{
+		ID:            uuid.New(),
+		ApologyID:     apology.ID,
+		StudentID:     apology.StudentID,
+		Status:        models.GuardianAckPending,
+		GuardianName:  sm.GuardianName,
+		GuardianEmail: sm.GuardianEmail,
+		GuardianPhone: sm.GuardianPhone,
+		LinkNonce:     helpers.NewLinkNonce(),
+		ExpiresAt:     time.Now().Add(time.Duration(cfg.ExpiryHours) * time.Hour),
+	}
+	if !sm.HasGuardianContact() {
+		ack.Status = models.GuardianAckUnreachable
+	}
+	return ack
+}
+
+// loadGuardianAck resolves the :token of an acknowledgement link to a pending acknowledgement.
+func loadGuardianAck(c *fiber.Ctx) (*models.GuardianAcknowledgement, string, error) {
+	id, nonce, err := helpers.ParseGuardianToken(c.Params("token"))
+	if errors.Is(err, helpers.ErrExpiredGuardianToken) {
+		return nil, "", c.Status(410).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return nil, "", c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	var ack models.GuardianAcknowledgement
+	if err := config.DB.First(&ack, "id = ?", id).Error; err != nil {
+		return nil, "", c.Status(404).JSON(fiber.Map{"error": helpers.ErrInvalidGuardianToken.Error()})
+	}
+	switch {
+	case ack.Status == models.GuardianAckAcknowledged:
+		return nil, "", c.Status(409).JSON(fiber.Map{"error": "This apology has already been acknowledged"})
+	case ack.Status != models.GuardianAckPending:
+		return nil, "", c.Status(410).JSON(fiber.Map{"error": helpers.ErrExpiredGuardianToken.Error()})
+	case ack.LinkNonce != nonce:
+		return nil, "", c.Status(410).JSON(fiber.Map{"error": "This link has been replaced by a newer one"})
+	}
+	return &ack, nonce, nil
+}
+
+// 👪 GUARDIAN (public, signed link) — The apology awaiting acknowledgement
+func GetGuardianAcknowledgement(c *fiber.Ctx) error {
+	ack, _, errResp := loadGuardianAck(c)
+	if ack == nil {
+		return errResp
+	}
+	var apology models.Apology
+	if err := config.DB.Preload("Student.User").First(&apology, "id = ?", ack.ApologyID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Apology not found"})
+	}
+	letter := apology.Letter
+	if letter == "" {
+		letter = apology.Message
+	}
+	return c.JSON(fiber.Map{
+		"student_name":       apology.Student.User.Name,
+		"student_identifier": apology.StudentIdentifier,
+		"block":              apology.Student.Block,
+		"type":               apology.ApologyType,
+		"submitted_at":       apology.CreatedAt,
+		"letter":             letter,
+		"description":        apology.Description,
+		"fields":             apology.Fields,
+		"guardian_name":      ack.GuardianName,
+		"expires_at":         ack.ExpiresAt,
+	})
+}
+
+// 👪 GUARDIAN (public, signed link) — Acknowledge the apology; the link cannot be used again
+func AcknowledgeApology(c *fiber.Ctx) error {
+	ack, nonce, errResp := loadGuardianAck(c)
+	if ack == nil {
+		return errResp
+	}
+	var input struct {
+		Name    string `json:"name"`
+		Comment string `json:"comment"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
+	}
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Please enter your name to acknowledge"})
+	}
+
+	var sm models.StudentModel
+	config.DB.Preload("User").Where("user_id = ?", ack.StudentID).First(&sm)
+
+	now := time.Now()
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.GuardianAcknowledgement{}).
+			Where("id = ? AND status = ? AND link_nonce = ?", ack.ID, models.GuardianAckPending, nonce).
+			Updates(map[string]interface{}{
+				"status":            models.GuardianAckAcknowledged,
+				"acknowledged_at":   now,
+				"acknowledged_name": input.Name,
+				"guardian_comment":  strings.TrimSpace(input.Comment),
+				"acknowledged_ip":   c.IP(),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errGuardianAckChanged
+		}
+		released := tx.Model(&models.Apology{}).
+			Where("id = ? AND status = ?", ack.ApologyID, models.ApologyAwaitingGuardian).
+			Updates(bumpVersion(map[string]interface{}{"status": models.ApologySubmitted}))
+		if released.Error != nil {
+			return released.Error
+		}
+		event := newApologyEvent(c, ack.ApologyID, models.ApologyEventGuardian, "", "", "Acknowledged by "+input.Name)
+		if released.RowsAffected > 0 {
+			event.FromStatus, event.ToStatus = models.ApologyAwaitingGuardian, models.ApologySubmitted
+		}
+		event.ActorRole = "guardian"
+		if err := tx.Create(&event).Error; err != nil {
+			return err
+		}
+		// tell the student and the block wardens the apology is ready for review
+		if err := jobs.EnqueueNotification(tx, models.NotificationFanout{
+			UserIDs:     []uuid.UUID{ack.StudentID},
+			Title:       "Guardian Acknowledged",
+			Message:     "Your guardian acknowledged your apology. It is now awaiting review.",
+			Type:        "info",
+			RelatedID:   &ack.ApologyID,
+			RelatedType: "apology",
+		}); err != nil {
+			return err
+		}
+		if sm.Block == "" {
+			return nil
+		}
+		return jobs.EnqueueNotification(tx, models.NotificationFanout{
+			Block:       sm.Block,
+			Title:       "Apology Ready for Review",
+			Message:     fmt.Sprintf("The guardian of %s (%s) acknowledged their apology.", sm.User.Name, sm.StudentIdentifier),
+			Type:        "info",
+			RelatedID:   &ack.ApologyID,
+			RelatedType: "apology",
+		})
+	})
+	if err == errGuardianAckChanged {
+		return c.Status(409).JSON(fiber.Map{"error": "This link has already been used"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to record acknowledgement"})
+	}
+
+	jobs.WakeOutbox()
+	return c.JSON(fiber.Map{"message": "Thank you. Your acknowledgement has been recorded."})
+}
+
+// 🧑‍💼 ADMIN — Queue a fresh acknowledgement link (earlier links stop working) and put a not yet
+// reviewed apology back on hold until the guardian acknowledges
+func ResendGuardianAck(c *fiber.Ctx) error {
+	var apology models.Apology
+	if err := config.DB.Preload("Student").Preload("GuardianAck").First(&apology, "id = ?", c.Params("id")).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Apology not found"})
+	}
+	if !canAccessStudentBlock(c, apology.Student.Block) {
+		return c.Status(403).JSON(fiber.Map{"error": "Forbidden: admin not authorized for this apology"})
+	}
+	version, ok := ifMatchVersion(c, apology.Version)
+	if !ok {
+		return preconditionRequired(c)
+	}
+	if version != apology.Version {
+		return preconditionFailed(c, apology.Version)
+	}
+	if apology.Status != models.ApologyAwaitingGuardian && apology.Status != models.ApologySubmitted {
+		return c.Status(409).JSON(fiber.Map{"error": fmt.Sprintf("cannot request acknowledgement for an apology that is %s", apology.Status)})
+	}
+	if apology.GuardianAck != nil && apology.GuardianAck.Status == models.GuardianAckAcknowledged {
+		return c.Status(409).JSON(fiber.Map{"error": "The guardian has already acknowledged this apology"})
+	}
+	sm := apology.Student
+	if !sm.HasGuardianContact() {
+		return c.Status(409).JSON(fiber.Map{"error": "The student has no guardian contact on file"})
+	}
+
+	cfg := jobs.GuardianConfigFromEnv()
+	ack := models.GuardianAcknowledgement{ID: uuid.New(), ApologyID: apology.ID, StudentID: apology.StudentID}
+	if apology.GuardianAck != nil {
+		ack = *apology.GuardianAck
+	}
+	ack.Status = models.GuardianAckPending
+	ack.GuardianName, ack.GuardianEmail, ack.GuardianPhone = sm.GuardianName, sm.GuardianEmail, sm.GuardianPhone
+	ack.LinkNonce = helpers.NewLinkNonce()
+	ack.ExpiresAt = time.Now().Add(time.Duration(cfg.ExpiryHours) * time.Hour)
+	ack.SentAt, ack.LastReminderAt, ack.RemindersSent = nil, nil, 0
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// the apology must still be where it was read, or a review could be pulled back
+		updated, err := updateIfVersion(tx.Where("status = ?", apology.Status), &models.Apology{}, apology.ID, version,
+			map[string]interface{}{"status": models.ApologyAwaitingGuardian})
+		if err != nil {
+			return err
+		}
+		if !updated {
+			return errApologyChanged
+		}
+		if err := tx.Save(&ack).Error; err != nil {
+			return err
+		}
+		event := newApologyEvent(c, apology.ID, models.ApologyEventGuardian, "", "", "Guardian acknowledgement link re-sent")
+		if apology.Status == models.ApologySubmitted {
+			event.FromStatus, event.ToStatus = models.ApologySubmitted, models.ApologyAwaitingGuardian
+		}
+		return tx.Create(&event).Error
+	})
+	if err == errApologyChanged {
+		return c.Status(409).JSON(fiber.Map{"error": "Apology was updated meanwhile; reload and retry"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to reset guardian acknowledgement"})
+	}
+	setETag(c, version+1)
+	// the guardian monitor sends the fresh link, as it does for new apologies
+	jobs.WakeGuardianAckMonitor()
+
+	config.DB.First(&ack, "id = ?", ack.ID)
+	return c.JSON(fiber.Map{"message": "Acknowledgement link queued", "data": ack})
+}
@@ -0,0 +1,168 @@
+package controllers
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/aditisaxena259/mental-health-be/config"
+	"github.com/aditisaxena259/mental-health-be/models"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Pending means waiting on the warden: complaints not yet resolved, apologies submitted or under
+// review. Apologies still waiting for a guardian are counted separately as they cannot be acted on yet.
+var (
+	inboxComplaintStatuses = []models.ComplaintStatus{models.Open, models.InProgress}
+	inboxApologyStatuses   = []models.ApologyStatus{models.ApologySubmitted, models.ApologyReviewed}
+)
+
+// inboxAgeBucket labels how long an item has been waiting, based on created_at of table.
+func inboxAgeBucket(table string) string {
+	return `CASE
+		WHEN ` + table + `.created_at > NOW() - INTERVAL '1 day' THEN 'under_1d'
+		WHEN ` + table + `.created_at > NOW() - INTERVAL '3 days' THEN '1_3d'
+		WHEN ` + table + `.created_at > NOW() - INTERVAL '7 days' THEN '3_7d'
+		ELSE 'over_7d' END`
+}
+
+// inboxCounts is the breakdown of one kind of pending item.
+type inboxCounts struct {
+	Total      int            `json:"total"`
+	ByType     map[string]int `json:"by_type"`
+	ByPriority map[string]int `json:"by_priority,omitempty"`
+	ByAge      map[string]int `json:"by_age"`
+	// AwaitingGuardian counts apologies on hold for a guardian acknowledgement
+	AwaitingGuardian *int `json:"awaiting_guardian,omitempty"`
+}
+
+func newInboxCounts(withPriority bool) *inboxCounts {
+	counts := &inboxCounts{
+		ByType: map[string]int{},
+		ByAge:  map[string]int{"under_1d": 0, "1_3d": 0, "3_7d": 0, "over_7d": 0},
+	}
+	if withPriority {
+		counts.ByPriority = map[string]int{}
+	}
+	return counts
+}
+
+type inboxBlock struct {
+	Block      string       `json:"block"`
+	Complaints *inboxCounts `json:"complaints"`
+	Apologies  *inboxCounts `json:"apologies"`
+}
+
+type inboxRow struct {
+	Block    string
+	Type     string
+	Priority string
+	Age      string
+	Status   string
+	Count    int
+}
+
+// 📥 ADMIN — Pending complaints and apologies for the warden's block by type, priority and age.
+// Chief admins get the totals plus a per-block breakdown. Filters: ?type=, ?priority= (complaints), ?block= (chief admin).
+func GetInbox(c *fiber.Ctx) error {
+	role, _ := c.Locals("role").(string)
+	block := requesterAdminBlock(c)
+	if block == "" && role != string(models.ChiefAdmin) {
+		return c.Status(403).JSON(fiber.Map{"error": "Forbidden: admin has no block assigned"})
+	}
+	if block == "" {
+		block = strings.ToUpper(strings.TrimSpace(c.Query("block")))
+	}
+	itemType, priority := c.Query("type"), c.Query("priority")
+	scope := func(db *gorm.DB) *gorm.DB {
+		if block != "" {
+			db = db.Where("student_models.block = ?", block)
+		}
+		return db
+	}
+
+	var complaintRows []inboxRow
+	complaintQuery := config.DB.Model(&models.Complaint{}).
+		Select("student_models.block, complaints.type, COALESCE(complaints.priority, 'medium') AS priority, "+
+			inboxAgeBucket("complaints")+" AS age, COUNT(*) AS count").
+		Joins("JOIN student_models ON student_models.user_id = complaints.user_id").
+		Where("complaints.status IN ?", inboxComplaintStatuses).
+		Scopes(scope).
+		Group("student_models.block, complaints.type, 3, 4")
+	if itemType != "" {
+		complaintQuery = complaintQuery.Where("complaints.type = ?", itemType)
+	}
+	if priority != "" {
+		complaintQuery = complaintQuery.Where("complaints.priority = ?", priority)
+	}
+	if err := complaintQuery.Scan(&complaintRows).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to count pending complaints"})
+	}
+
+	var apologyRows []inboxRow
+	apologyStatuses := append([]models.ApologyStatus{models.ApologyAwaitingGuardian}, inboxApologyStatuses...)
+	apologyQuery := config.DB.Model(&models.Apology{}).
+		Select("student_models.block, apologies.apology_type AS type, apologies.status::text AS status, "+
+			inboxAgeBucket("apologies")+" AS age, COUNT(*) AS count").
+		Joins("JOIN student_models ON student_models.user_id = apologies.student_id").
+		Where("apologies.status IN ?", apologyStatuses).
+		Scopes(scope).
+		Group("student_models.block, apologies.apology_type, apologies.status, 4")
+	if itemType != "" {
+		apologyQuery = apologyQuery.Where("apologies.apology_type = ?", itemType)
+	}
+	// apologies have no priority, so a priority filter leaves none
+	if priority == "" {
+		if err := apologyQuery.Scan(&apologyRows).Error; err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to count pending apologies"})
+		}
+	}
+
+	complaints, apologies := newInboxCounts(true), newInboxCounts(false)
+	awaiting := 0
+	apologies.AwaitingGuardian = &awaiting
+	blocks := map[string]*inboxBlock{}
+	blockFor := func(name string) *inboxBlock {
+		if b, ok := blocks[name]; ok {
+			return b
+		}
+		b := &inboxBlock{Block: name, Complaints: newInboxCounts(true), Apologies: newInboxCounts(false)}
+		zero := 0
+		b.Apologies.AwaitingGuardian = &zero
+		blocks[name] = b
+		return b
+	}
+	for _, row := range complaintRows {
+		for _, counts := range []*inboxCounts{complaints, blockFor(row.Block).Complaints} {
+			counts.Total += row.Count
+			counts.ByType[row.Type] += row.Count
+			counts.ByPriority[row.Priority] += row.Count
+			counts.ByAge[row.Age] += row.Count
+		}
+	}
+	for _, row := range apologyRows {
+		for _, counts := range []*inboxCounts{apologies, blockFor(row.Block).Apologies} {
+			if row.Status == string(models.ApologyAwaitingGuardian) {
+				*counts.AwaitingGuardian += row.Count
+				continue
+			}
+			counts.Total += row.Count
+			counts.ByType[row.Type] += row.Count
+			counts.ByAge[row.Age] += row.Count
+		}
+	}
+
+	resp := fiber.Map{"complaints": complaints, "apologies": apologies}
+	if role == string(models.ChiefAdmin) {
+		breakdown := make([]*inboxBlock, 0, len(blocks))
+		for _, b := range blocks {
+			breakdown = append(breakdown, b)
+		}
+		sort.Slice(breakdown, func(i, j int) bool { return breakdown[i].Block < breakdown[j].Block })
+		resp["blocks"] = breakdown
+	}
+	if block != "" {
+		resp["block"] = block
+	}
+	return c.JSON(resp)
+}
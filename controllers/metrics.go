@@ -43,3 +43,39 @@ func GetPendingComplaint(c *fiber.Ctx) error {
 		Count(&count)
 	return c.JSON(fiber.Map{"pending_count": count})
 }
+
+// GET /metrics/resolution-time
+// Average hours from complaint creation to its first response (first status change by staff,
+// so a student withdrawing or reopening does not count) and to resolution, computed from typed
+// timeline events.
+func GetResolutionTime(c *fiber.Ctx) error {
+	var result struct {
+		AvgFirstResponseHours *float64
+		AvgResolutionHours    *float64
+		ResolvedCount         int64
+	}
+	err := config.DB.Raw(`
+		SELECT
+			AVG(EXTRACT(EPOCH FROM (first_change.ts - complaints.created_at)) / 3600) AS avg_first_response_hours,
+			AVG(EXTRACT(EPOCH FROM (resolved.ts - complaints.created_at)) / 3600) AS avg_resolution_hours,
+			COUNT(resolved.ts) AS resolved_count
+		FROM complaints
+		LEFT JOIN (
+			SELECT complaint_id, MIN(timestamp) AS ts FROM timeline_entries
+			WHERE event_type = ? AND author <> ? GROUP BY complaint_id
+		) first_change ON first_change.complaint_id = complaints.id
+		LEFT JOIN (
+			SELECT complaint_id, MAX(timestamp) AS ts FROM timeline_entries
+			WHERE event_type = ? AND new_value = ? GROUP BY complaint_id
+		) resolved ON resolved.complaint_id = complaints.id
+		WHERE complaints.deleted_at IS NULL`,
+		models.EventStatusChange, models.Student, models.EventStatusChange, models.Resolved).Scan(&result).Error
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to compute resolution time"})
+	}
+	return c.JSON(fiber.Map{
+		"avg_first_response_hours": result.AvgFirstResponseHours,
+		"avg_resolution_hours":     result.AvgResolutionHours,
+		"resolved_count":           result.ResolvedCount,
+	})
+}
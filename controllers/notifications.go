@@ -1,9 +1,14 @@
 package controllers
 
 import (
+	"bufio"
+	"encoding/json"
+	"fmt"
 	"os"
+	"time"
 
 	"github.com/aditisaxena259/mental-health-be/config"
+	"github.com/aditisaxena259/mental-health-be/helpers"
 	"github.com/aditisaxena259/mental-health-be/models"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -29,6 +34,66 @@ func GetNotifications(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{"unreadCount": unread, "data": notes})
 }
 
+// notificationHeartbeat keeps idle streams open through proxies that drop silent connections.
+const notificationHeartbeat = 25 * time.Second
+
+// GET /api/notifications/stream - Server-Sent Events for the logged-in user: "notification" with each
+// new notification and "unread_count" whenever the count changes (sent once on connect as well)
+func StreamNotifications(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	var unread int64
+	if err := config.DB.Model(&models.Notification{}).Where("user_id = ? AND is_read = false", uid).Count(&unread).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "failed to fetch notifications"})
+	}
+
+	// subscribe before answering so nothing created in between is missed
+	events, cancel := helpers.Notifications().Subscribe(uid)
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+		if writeSSE(w, helpers.PushEvent{Name: "unread_count", Data: fiber.Map{"unreadCount": unread}}) != nil {
+			return
+		}
+		heartbeat := time.NewTicker(notificationHeartbeat)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case ev := <-events:
+				if writeSSE(w, ev) != nil {
+					return
+				}
+			case <-heartbeat.C:
+				// a write to a closed connection is how a gone client is noticed
+				if _, err := w.WriteString(": ping\n\n"); err != nil || w.Flush() != nil {
+					return
+				}
+			}
+		}
+	})
+	return nil
+}
+
+func writeSSE(w *bufio.Writer, ev helpers.PushEvent) error {
+	data, err := json.Marshal(ev.Data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Name, data); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
 // PATCH /api/notifications/:id/read - mark a notification as read for the logged-in user
 func MarkNotificationRead(c *fiber.Ctx) error {
 	id := c.Params("id")
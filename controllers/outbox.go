@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/aditisaxena259/mental-health-be/config"
+	"github.com/aditisaxena259/mental-health-be/jobs"
+	"github.com/aditisaxena259/mental-health-be/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// ☠️ CHIEF ADMIN — Outbox events that ran out of attempts, newest first
+func GetDeadOutboxEvents(c *fiber.Ctx) error {
+	var events []models.OutboxEvent
+	if err := config.DB.Where("status = ?", models.OutboxDead).Order("updated_at desc").Find(&events).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch outbox events"})
+	}
+	return c.JSON(fiber.Map{"count": len(events), "data": events})
+}
+
+// 🔁 CHIEF ADMIN — Queue a dead-lettered outbox event for another round of attempts
+func RetryOutboxEvent(c *fiber.Ctx) error {
+	eventID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid outbox event id"})
+	}
+	res := config.DB.Model(&models.OutboxEvent{}).
+		Where("id = ? AND status = ?", eventID, models.OutboxDead).
+		Updates(map[string]interface{}{"status": models.OutboxPending, "attempts": 0, "next_attempt_at": time.Now()})
+	if res.Error != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to retry outbox event"})
+	}
+	if res.RowsAffected == 0 {
+		return c.Status(404).JSON(fiber.Map{"error": "Dead-lettered outbox event not found"})
+	}
+	jobs.WakeOutbox()
+	return c.JSON(fiber.Map{"message": "Outbox event queued for retry"})
+}
@@ -0,0 +1,419 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aditisaxena259/mental-health-be/config"
+	"github.com/aditisaxena259/mental-health-be/jobs"
+	"github.com/aditisaxena259/mental-health-be/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var errOutpassChanged = errors.New("outpass changed concurrently")
+
+// outpassLateGrace is how long after the expected return a check-in still counts as on time.
+// Env: OUTPASS_LATE_GRACE_MINUTES (default 15).
+func outpassLateGrace() time.Duration {
+	if v, err := strconv.Atoi(os.Getenv("OUTPASS_LATE_GRACE_MINUTES")); err == nil && v >= 0 {
+		return time.Duration(v) * time.Minute
+	}
+	return 15 * time.Minute
+}
+
+// outpassView adds the derived flags clients need to an outpass.
+type outpassView struct {
+	models.Outpass
+	// Overdue: checked out and past the expected return (plus grace) without checking back in
+	Overdue bool `json:"overdue"`
+	// ApologyDue: returned late and no outing apology has been filed for it yet
+	ApologyDue bool `json:"apology_due"`
+}
+
+func outpassViews(outpasses []models.Outpass) []outpassView {
+	deadline := time.Now().Add(-outpassLateGrace())
+	views := make([]outpassView, 0, len(outpasses))
+	for _, o := range outpasses {
+		views = append(views, outpassView{
+			Outpass:    o,
+			Overdue:    o.Status == models.OutpassCheckedOut && o.ExpectedReturnAt.Before(deadline),
+			ApologyDue: o.IsLate && o.Apology == nil,
+		})
+	}
+	return views
+}
+
+// requesterGateBlock returns the block the requester is restricted to on outpass routes. Security
+// staff work the gate of their own block; everyone else is scoped as by requesterAdminBlock.
+func requesterGateBlock(c *fiber.Ctx) string {
+	role, _ := c.Locals("role").(string)
+	if role != string(models.Security) {
+		return requesterAdminBlock(c)
+	}
+	userID, _ := c.Locals("user_id").(string)
+	var guard models.User
+	if userID == "" || config.DB.First(&guard, "id = ?", userID).Error != nil {
+		return ""
+	}
+	return strings.TrimSpace(guard.Block)
+}
+
+// canAccessOutpassBlock is canAccessStudentBlock extended to security staff of the student's block.
+func canAccessOutpassBlock(c *fiber.Ctx, studentBlock string) bool {
+	if role, _ := c.Locals("role").(string); role != string(models.Security) {
+		return canAccessStudentBlock(c, studentBlock)
+	}
+	gateBlock := requesterGateBlock(c)
+	return gateBlock != "" && gateBlock == strings.TrimSpace(studentBlock)
+}
+
+// outpassScope restricts outpass queries to the requester's block; ?status=, ?block= and ?late=true filter further.
+func outpassScope(c *fiber.Ctx) func(db *gorm.DB) *gorm.DB {
+	adminBlock := requesterGateBlock(c)
+	role, _ := c.Locals("role").(string)
+	status, block, late := c.Query("status"), c.Query("block"), c.Query("late")
+	return func(db *gorm.DB) *gorm.DB {
+		if status != "" {
+			db = db.Where("outpasses.status = ?", status)
+		}
+		if block != "" {
+			db = db.Where("outpasses.student_id IN (SELECT user_id FROM student_models WHERE block = ?)", strings.ToUpper(block))
+		}
+		if late == "true" {
+			db = db.Where("outpasses.is_late = ?", true)
+		}
+		if adminBlock != "" {
+			db = db.Where("outpasses.student_id IN (SELECT user_id FROM student_models WHERE block = ?)", adminBlock)
+		} else if role == string(models.Admin) || role == string(models.Security) {
+			db = db.Where("1 = 0")
+		}
+		return db
+	}
+}
+
+// loadOutpass fetches :id with its student and checks the requester's block.
+func loadOutpass(c *fiber.Ctx) (*models.Outpass, error) {
+	var outpass models.Outpass
+	if err := config.DB.Preload("Student.User").Preload("Apology").First(&outpass, "id = ?", c.Params("id")).Error; err != nil {
+		return nil, c.Status(404).JSON(fiber.Map{"error": "Outpass not found"})
+	}
+	if !canAccessOutpassBlock(c, outpass.Student.Block) {
+		return nil, c.Status(403).JSON(fiber.Map{"error": "Forbidden: not authorized for this outpass"})
+	}
+	return &outpass, nil
+}
+
+// loadLateOutpassForApology checks that an apology may be filed against the student's outpass:
+// it must have been returned late and not already have an apology.
+func loadLateOutpassForApology(c *fiber.Ctx, studentID uuid.UUID, outpassID string) (*models.Outpass, error) {
+	id, err := uuid.Parse(outpassID)
+	if err != nil {
+		return nil, c.Status(400).JSON(fiber.Map{"error": "Invalid outpass_id"})
+	}
+	var outpass models.Outpass
+	if err := config.DB.Preload("Apology").First(&outpass, "id = ? AND student_id = ?", id, studentID).Error; err != nil {
+		return nil, c.Status(404).JSON(fiber.Map{"error": "Outpass not found"})
+	}
+	if !outpass.IsLate {
+		return nil, c.Status(409).JSON(fiber.Map{"error": "Apologies can only be filed for late returns"})
+	}
+	if outpass.Apology != nil {
+		return nil, c.Status(409).JSON(fiber.Map{"error": "An apology has already been filed for this outpass"})
+	}
+	return &outpass, nil
+}
+
+// moveOutpass applies changes to an outpass only if it is still in status from.
+func moveOutpass(tx *gorm.DB, outpass *models.Outpass, from models.OutpassStatus, changes map[string]interface{}) error {
+	res := tx.Model(&models.Outpass{}).Where("id = ? AND status = ?", outpass.ID, from).Updates(changes)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errOutpassChanged
+	}
+	return nil
+}
+
+// notifyOutpass queues a notification about an outpass to its student in tx.
+func notifyOutpass(tx *gorm.DB, userID, outpassID uuid.UUID, title, message, ntype string) error {
+	return jobs.EnqueueNotification(tx, models.NotificationFanout{
+		UserIDs:     []uuid.UUID{userID},
+		Title:       title,
+		Message:     message,
+		Type:        ntype,
+		RelatedID:   &outpassID,
+		RelatedType: "outpass",
+	})
+}
+
+// 🧑‍🎓 STUDENT — Request an outpass (destination, departure and expected return as RFC3339)
+func CreateOutpass(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	studentID, err := uuid.Parse(userID)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized: missing user ID"})
+	}
+	var input struct {
+		Destination      string    `json:"destination"`
+		Reason           string    `json:"reason"`
+		DepartureAt      time.Time `json:"departure_at"`
+		ExpectedReturnAt time.Time `json:"expected_return_at"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid input", "details": err.Error()})
+	}
+	input.Destination = strings.TrimSpace(input.Destination)
+	if input.Destination == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "destination is required"})
+	}
+	if input.DepartureAt.IsZero() || input.ExpectedReturnAt.IsZero() {
+		return c.Status(400).JSON(fiber.Map{"error": "departure_at and expected_return_at are required"})
+	}
+	if !input.ExpectedReturnAt.After(input.DepartureAt) {
+		return c.Status(400).JSON(fiber.Map{"error": "expected_return_at must be after departure_at"})
+	}
+	if !input.ExpectedReturnAt.After(time.Now()) {
+		return c.Status(400).JSON(fiber.Map{"error": "expected_return_at must be in the future"})
+	}
+
+	var open int64
+	config.DB.Model(&models.Outpass{}).
+		Where("student_id = ? AND status IN ?", studentID, []models.OutpassStatus{models.OutpassPending, models.OutpassApproved, models.OutpassCheckedOut}).
+		Count(&open)
+	if open > 0 {
+		return c.Status(409).JSON(fiber.Map{"error": "You already have an active outpass"})
+	}
+
+	outpass := models.Outpass{
+		ID:               uuid.New(),
+		StudentID:        studentID,
+		Destination:      input.Destination,
+		Reason:           strings.TrimSpace(input.Reason),
+		DepartureAt:      input.DepartureAt,
+		ExpectedReturnAt: input.ExpectedReturnAt,
+		Status:           models.OutpassPending,
+	}
+	var sm models.StudentModel
+	config.DB.Preload("User").Where("user_id = ?", studentID).First(&sm)
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Student", "Apology").Create(&outpass).Error; err != nil {
+			return err
+		}
+		if sm.Block == "" {
+			return nil
+		}
+		// notify the block wardens
+		return jobs.EnqueueNotification(tx, models.NotificationFanout{
+			Block:       sm.Block,
+			Title:       "New Outpass Request",
+			Message:     fmt.Sprintf("%s (%s) requested an outpass to %s", sm.User.Name, sm.StudentIdentifier, outpass.Destination),
+			Type:        "info",
+			RelatedID:   &outpass.ID,
+			RelatedType: "outpass",
+		})
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to request outpass"})
+	}
+	jobs.WakeOutbox()
+
+	return c.Status(201).JSON(fiber.Map{"message": "Outpass requested", "data": outpass})
+}
+
+// 🧑‍🎓 STUDENT — Own outpasses, newest first
+func GetMyOutpasses(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	var outpasses []models.Outpass
+	if err := config.DB.Preload("Apology").Where("student_id = ?", userID).
+		Order("created_at desc").Find(&outpasses).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch outpasses"})
+	}
+	return c.JSON(fiber.Map{"count": len(outpasses), "data": outpassViews(outpasses)})
+}
+
+// 🧑‍🎓 STUDENT — Cancel a pending or approved outpass before leaving
+func CancelOutpass(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	var outpass models.Outpass
+	if err := config.DB.First(&outpass, "id = ? AND student_id = ?", c.Params("id"), userID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Outpass not found"})
+	}
+	if !outpass.Status.CanTransitionTo(models.OutpassCancelled) {
+		return c.Status(409).JSON(fiber.Map{"error": fmt.Sprintf("cannot cancel an outpass that is %s", outpass.Status)})
+	}
+	if err := moveOutpass(config.DB, &outpass, outpass.Status, map[string]interface{}{"status": models.OutpassCancelled}); err != nil {
+		if err == errOutpassChanged {
+			return c.Status(409).JSON(fiber.Map{"error": "Outpass was updated meanwhile; reload and retry"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to cancel outpass"})
+	}
+	outpass.Status = models.OutpassCancelled
+	return c.JSON(fiber.Map{"message": "Outpass cancelled", "data": outpass})
+}
+
+// 🧑‍💼 ADMIN — Outpasses of the warden's block (?status=, ?block= for chief, ?late=true)
+func GetOutpasses(c *fiber.Ctx) error {
+	var outpasses []models.Outpass
+	if err := config.DB.Preload("Student.User").Preload("Apology").
+		Scopes(outpassScope(c)).
+		Order("outpasses.departure_at asc").
+		Find(&outpasses).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch outpasses"})
+	}
+	return c.JSON(fiber.Map{"count": len(outpasses), "data": outpassViews(outpasses)})
+}
+
+// 🧑‍💼 ADMIN — Approve or reject a pending outpass; a comment is required to reject
+func ReviewOutpass(c *fiber.Ctx) error {
+	var input struct {
+		Status  models.OutpassStatus `json:"status"`
+		Comment string               `json:"comment"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
+	}
+	input.Comment = strings.TrimSpace(input.Comment)
+	if input.Status != models.OutpassApproved && input.Status != models.OutpassRejected {
+		return c.Status(400).JSON(fiber.Map{"error": "status must be approved or rejected"})
+	}
+	if input.Status == models.OutpassRejected && input.Comment == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "A comment is required when rejecting an outpass"})
+	}
+
+	outpass, errResp := loadOutpass(c)
+	if outpass == nil {
+		return errResp
+	}
+	if outpass.Status != models.OutpassPending {
+		return c.Status(409).JSON(fiber.Map{"error": fmt.Sprintf("cannot review an outpass that is %s", outpass.Status)})
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	reviewerID, _ := uuid.Parse(userID)
+	now := time.Now()
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := moveOutpass(tx, outpass, models.OutpassPending, map[string]interface{}{
+			"status":         input.Status,
+			"reviewed_by_id": reviewerID,
+			"review_comment": input.Comment,
+			"reviewed_at":    now,
+		}); err != nil {
+			return err
+		}
+		if input.Status == models.OutpassApproved {
+			return notifyOutpass(tx, outpass.StudentID, outpass.ID, "Outpass Approved",
+				fmt.Sprintf("Your outpass to %s was approved. Return by %s.", outpass.Destination, outpass.ExpectedReturnAt.Format("02 Jan 15:04")), "success")
+		}
+		return notifyOutpass(tx, outpass.StudentID, outpass.ID, "Outpass Rejected",
+			fmt.Sprintf("Your outpass to %s was rejected: %s", outpass.Destination, input.Comment), "warning")
+	})
+	if err == errOutpassChanged {
+		return c.Status(409).JSON(fiber.Map{"error": "Outpass was updated meanwhile; reload and retry"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to review outpass"})
+	}
+	jobs.WakeOutbox()
+
+	config.DB.Preload("Student.User").First(outpass, "id = ?", outpass.ID)
+	return c.JSON(fiber.Map{"message": "Outpass " + string(input.Status), "data": outpass})
+}
+
+// 🚪 GATE — Approved and checked-out outpasses of the gate's block (?student= identifier search)
+func GetGateOutpasses(c *fiber.Ctx) error {
+	query := config.DB.Preload("Student.User").
+		Scopes(outpassScope(c)).
+		Where("outpasses.status IN ?", []models.OutpassStatus{models.OutpassApproved, models.OutpassCheckedOut})
+	if student := strings.TrimSpace(c.Query("student")); student != "" {
+		query = query.Where("outpasses.student_id IN (SELECT user_id FROM student_models WHERE student_identifier = ?)", student)
+	}
+	var outpasses []models.Outpass
+	if err := query.Order("outpasses.expected_return_at asc").Find(&outpasses).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch outpasses"})
+	}
+	return c.JSON(fiber.Map{"count": len(outpasses), "data": outpassViews(outpasses)})
+}
+
+// 🚪 GATE — Record that the student left through the gate
+func CheckOutOutpass(c *fiber.Ctx) error {
+	outpass, errResp := loadOutpass(c)
+	if outpass == nil {
+		return errResp
+	}
+	if !outpass.Status.CanTransitionTo(models.OutpassCheckedOut) {
+		return c.Status(409).JSON(fiber.Map{"error": fmt.Sprintf("cannot check out an outpass that is %s", outpass.Status)})
+	}
+	now := time.Now()
+	if !outpass.ExpectedReturnAt.After(now) {
+		return c.Status(409).JSON(fiber.Map{"error": "Outpass has expired; the student must request a new one"})
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	guardID, _ := uuid.Parse(userID)
+	err := moveOutpass(config.DB, outpass, models.OutpassApproved, map[string]interface{}{
+		"status":            models.OutpassCheckedOut,
+		"checked_out_at":    now,
+		"checked_out_by_id": guardID,
+	})
+	if err == errOutpassChanged {
+		return c.Status(409).JSON(fiber.Map{"error": "Outpass was updated meanwhile; reload and retry"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to check out"})
+	}
+
+	config.DB.Preload("Student.User").First(outpass, "id = ?", outpass.ID)
+	return c.JSON(fiber.Map{"message": "Checked out", "data": outpass})
+}
+
+// 🚪 GATE — Record the student's return; a late return prompts an outing apology linked to the outpass
+func CheckInOutpass(c *fiber.Ctx) error {
+	outpass, errResp := loadOutpass(c)
+	if outpass == nil {
+		return errResp
+	}
+	if !outpass.Status.CanTransitionTo(models.OutpassReturned) {
+		return c.Status(409).JSON(fiber.Map{"error": fmt.Sprintf("cannot check in an outpass that is %s", outpass.Status)})
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	guardID, _ := uuid.Parse(userID)
+	now := time.Now()
+	late := now.After(outpass.ExpectedReturnAt.Add(outpassLateGrace()))
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := moveOutpass(tx, outpass, models.OutpassCheckedOut, map[string]interface{}{
+			"status":           models.OutpassReturned,
+			"checked_in_at":    now,
+			"checked_in_by_id": guardID,
+			"is_late":          late,
+		}); err != nil || !late {
+			return err
+		}
+		minutes := int(now.Sub(outpass.ExpectedReturnAt).Minutes())
+		return notifyOutpass(tx, outpass.StudentID, outpass.ID, "Outing Apology Required",
+			fmt.Sprintf("You returned %d minute(s) after your outpass to %s expired. Please submit an outing apology for this outpass.", minutes, outpass.Destination), "warning")
+	})
+	if err == errOutpassChanged {
+		return c.Status(409).JSON(fiber.Map{"error": "Outpass was updated meanwhile; reload and retry"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to check in"})
+	}
+	if late {
+		jobs.WakeOutbox()
+	}
+
+	config.DB.Preload("Student.User").First(outpass, "id = ?", outpass.ID)
+	resp := fiber.Map{"message": "Checked in", "data": outpass}
+	if late {
+		resp["apology_prompt"] = fiber.Map{"type": models.ApologyForOuting, "outpass_id": outpass.ID}
+	}
+	return c.JSON(resp)
+}
@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aditisaxena259/mental-health-be/config"
+	"github.com/aditisaxena259/mental-health-be/helpers"
+	"github.com/aditisaxena259/mental-health-be/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// reportMonth parses ?month=YYYY-MM (default: the current month) into its [start, end) range.
+func reportMonth(c *fiber.Ctx) (time.Time, time.Time, error) {
+	now := time.Now()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	if m := c.Query("month"); m != "" {
+		parsed, err := time.ParseInLocation("2006-01", m, now.Location())
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("month must be YYYY-MM")
+		}
+		start = parsed
+	}
+	return start, start.AddDate(0, 1, 0), nil
+}
+
+// reportScope describes which blocks a report covers for the requester.
+func reportScope(c *fiber.Ctx) string {
+	if block := requesterAdminBlock(c); block != "" {
+		return "Block " + block
+	}
+	if block := c.Query("block"); block != "" {
+		return "Block " + block
+	}
+	return "All blocks"
+}
+
+// sendPDF streams a generated report as a download.
+func sendPDF(c *fiber.Ctx, filename string, buf *bytes.Buffer) error {
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+	return c.SendStream(buf, buf.Len())
+}
+
+// 📄 ADMIN — Monthly apology report as PDF (?month=YYYY-MM plus the apology list filters)
+func GetApologyReportPDF(c *fiber.Ctx) error {
+	start, end, err := reportMonth(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var apologies []models.Apology
+	if err := config.DB.Preload("Student.User").
+		Scopes(apologyFilterScope(c)).
+		Where("apologies.created_at >= ? AND apologies.created_at < ?", start, end).
+		Order("apologies.created_at asc").
+		Find(&apologies).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load apologies"})
+	}
+
+	var buf bytes.Buffer
+	if err := helpers.CreateApologyReport(&buf, start.Format("January 2006"), reportScope(c), apologies); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to generate report", "details": err.Error()})
+	}
+	return sendPDF(c, fmt.Sprintf("apology-report-%s.pdf", start.Format("2006-01")), &buf)
+}
+
+// 📄 ADMIN — Complaint summary per block as PDF (?month=YYYY-MM plus the complaint list filters)
+func GetComplaintSummaryPDF(c *fiber.Ctx) error {
+	start, end, err := reportMonth(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	filter := complaintFilterFromQuery(c)
+	filter.From, filter.To = start.Format("2006-01-02"), end.AddDate(0, 0, -1).Format("2006-01-02")
+
+	var counts []struct {
+		Block   string
+		Status  string
+		Type    string
+		Count   int
+		Overdue int
+	}
+	if err := config.DB.Model(&models.Complaint{}).
+		Select(`student_models.block, complaints.status::text AS status, complaints.type, COUNT(*) AS count,
+			COUNT(*) FILTER (WHERE complaints.due_at < NOW() AND complaints.status IN ('open', 'inprogress')) AS overdue`).
+		Joins("JOIN student_models ON student_models.user_id = complaints.user_id").
+		Scopes(filter.scope(c)).
+		Group("student_models.block, complaints.status, complaints.type").
+		Order("student_models.block").
+		Scan(&counts).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to summarize complaints"})
+	}
+
+	var resolution []struct {
+		Block string
+		Hours float64
+	}
+	if err := config.DB.Model(&models.Complaint{}).
+		Select("student_models.block, AVG(EXTRACT(EPOCH FROM (resolved.ts - complaints.created_at)) / 3600) AS hours").
+		Joins("JOIN student_models ON student_models.user_id = complaints.user_id").
+		Joins(`JOIN (
+			SELECT complaint_id, MAX(timestamp) AS ts FROM timeline_entries
+			WHERE event_type = ? AND new_value = ? GROUP BY complaint_id
+		) resolved ON resolved.complaint_id = complaints.id`, models.EventStatusChange, models.Resolved).
+		Scopes(filter.scope(c)).
+		Group("student_models.block").
+		Scan(&resolution).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to summarize complaints"})
+	}
+
+	summaries := []helpers.BlockComplaintSummary{}
+	index := map[string]int{}
+	byStatus := map[string]map[string]int{}
+	byType := map[string]map[string]int{}
+	for _, row := range counts {
+		i, ok := index[row.Block]
+		if !ok {
+			i = len(summaries)
+			index[row.Block] = i
+			summaries = append(summaries, helpers.BlockComplaintSummary{Block: row.Block})
+			byStatus[row.Block] = map[string]int{}
+			byType[row.Block] = map[string]int{}
+		}
+		summaries[i].Total += row.Count
+		summaries[i].Overdue += row.Overdue
+		byStatus[row.Block][row.Status] += row.Count
+		byType[row.Block][row.Type] += row.Count
+	}
+	for _, r := range resolution {
+		if i, ok := index[r.Block]; ok {
+			hours := r.Hours
+			summaries[i].AvgResolutionHours = &hours
+		}
+	}
+	for i := range summaries {
+		s := &summaries[i]
+		for _, status := range []models.ComplaintStatus{models.Open, models.InProgress, models.Resolved, models.Withdrawn} {
+			s.ByStatus = append(s.ByStatus, helpers.ChartBar{Label: string(status), Value: byStatus[s.Block][string(status)]})
+		}
+		for t, n := range byType[s.Block] {
+			s.ByType = append(s.ByType, helpers.ChartBar{Label: t, Value: n})
+		}
+		sortBars(s.ByType)
+	}
+
+	var buf bytes.Buffer
+	if err := helpers.CreateComplaintSummaryReport(&buf, start.Format("January 2006"), reportScope(c), summaries); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to generate report", "details": err.Error()})
+	}
+	return sendPDF(c, fmt.Sprintf("complaint-summary-%s.pdf", start.Format("2006-01")), &buf)
+}
+
+// sortBars orders bars by value, largest first, then by label.
+func sortBars(bars []helpers.ChartBar) {
+	sort.Slice(bars, func(i, j int) bool {
+		if bars[i].Value != bars[j].Value {
+			return bars[i].Value > bars[j].Value
+		}
+		return bars[i].Label < bars[j].Label
+	})
+}
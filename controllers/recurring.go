@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"strings"
+
+	"github.com/aditisaxena259/mental-health-be/config"
+	"github.com/aditisaxena259/mental-health-be/jobs"
+	"github.com/aditisaxena259/mental-health-be/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// 🔁 CHIEF ADMIN — Recurring issues report (optional ?status= and ?block= filters)
+func GetRecurringIssues(c *fiber.Ctx) error {
+	query := config.DB.Preload("Room").Preload("Asset").Order("complaint_count desc, last_seen_at desc")
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if block := c.Query("block"); block != "" {
+		query = query.Where("block = ?", strings.ToUpper(block))
+	}
+
+	var issues []models.RecurringIssue
+	if err := query.Find(&issues).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch recurring issues"})
+	}
+	return c.JSON(fiber.Map{"count": len(issues), "data": issues})
+}
+
+// 🔁 CHIEF ADMIN — A recurring issue with the complaints behind it
+func GetRecurringIssue(c *fiber.Ctx) error {
+	var issue models.RecurringIssue
+	if err := config.DB.Preload("Room").Preload("Asset").First(&issue, "id = ?", c.Params("id")).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Recurring issue not found"})
+	}
+
+	query := config.DB.Preload("Timeline").
+		Where("room_id = ? AND type = ? AND created_at BETWEEN ? AND ?", issue.RoomID, issue.Type, issue.FirstSeenAt, issue.LastSeenAt).
+		Order("created_at asc")
+	if issue.AssetID != nil {
+		query = query.Where("asset_id = ?", *issue.AssetID)
+	}
+	var complaints []models.Complaint
+	if err := query.Find(&complaints).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load complaints"})
+	}
+	redactComplaintsForStaff(c, complaints)
+	return c.JSON(fiber.Map{"data": issue, "complaints": complaints})
+}
+
+// 🔁 CHIEF ADMIN — Acknowledge or close a recurring issue
+func UpdateRecurringIssue(c *fiber.Ctx) error {
+	var input struct {
+		Status models.RecurringIssueStatus `json:"status"`
+		Note   string                      `json:"note"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
+	}
+	switch input.Status {
+	case models.RecurringOpen, models.RecurringAcknowledged, models.RecurringClosed:
+	default:
+		return c.Status(400).JSON(fiber.Map{"error": "status must be open, acknowledged or closed"})
+	}
+
+	var issue models.RecurringIssue
+	if err := config.DB.First(&issue, "id = ?", c.Params("id")).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Recurring issue not found"})
+	}
+	if err := config.DB.Model(&issue).Updates(map[string]interface{}{"status": input.Status, "note": input.Note}).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update recurring issue"})
+	}
+	return c.JSON(fiber.Map{"message": "Recurring issue updated", "data": issue})
+}
+
+// 🔁 CHIEF ADMIN — Run the recurring-issue analyzer now
+func ScanRecurringIssues(c *fiber.Ctx) error {
+	created, err := jobs.AnalyzeRecurringIssues(jobs.RecurringConfigFromEnv())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Recurring-issue analysis failed", "details": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Analysis complete", "created": created})
+}
@@ -0,0 +1,405 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/aditisaxena259/mental-health-be/config"
+	"github.com/aditisaxena259/mental-health-be/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type roomInput struct {
+	Block    string   `json:"block"`
+	Number   string   `json:"number"`
+	Floor    int      `json:"floor"`
+	Capacity int      `json:"capacity"`
+	Fixtures []string `json:"fixtures"` // asset kinds, e.g. ["geyser", "fan", "fan", "ac"]
+}
+
+func (in *roomInput) normalize() error {
+	in.Block = strings.ToUpper(strings.TrimSpace(in.Block))
+	in.Number = strings.TrimSpace(in.Number)
+	if len(in.Block) != 1 || in.Block[0] < 'A' || in.Block[0] > 'Z' {
+		return errors.New("block must be a single letter A-Z")
+	}
+	if in.Number == "" {
+		return errors.New("room number is required")
+	}
+	if in.Capacity <= 0 {
+		in.Capacity = 1
+	}
+	for i := range in.Fixtures {
+		in.Fixtures[i] = strings.ToLower(strings.TrimSpace(in.Fixtures[i]))
+	}
+	return nil
+}
+
+// upsertRoom creates or updates a room by (block, number) and makes sure it has at least as
+// many assets of each kind as listed in Fixtures. Existing assets are never removed.
+func upsertRoom(tx *gorm.DB, in roomInput) (*models.Room, error) {
+	block := models.HostelBlock{Code: in.Block}
+	if err := tx.Where("code = ?", in.Block).FirstOrCreate(&block).Error; err != nil {
+		return nil, err
+	}
+
+	var room models.Room
+	err := tx.Where("block = ? AND number = ?", in.Block, in.Number).First(&room).Error
+	switch {
+	case err == gorm.ErrRecordNotFound:
+		room = models.Room{ID: uuid.New(), Block: in.Block, Number: in.Number, Floor: in.Floor, Capacity: in.Capacity}
+		if err := tx.Create(&room).Error; err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	default:
+		if err := tx.Model(&room).Updates(map[string]interface{}{"floor": in.Floor, "capacity": in.Capacity}).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	wanted := map[string]int{}
+	for _, kind := range in.Fixtures {
+		if kind != "" {
+			wanted[kind]++
+		}
+	}
+	for kind, n := range wanted {
+		var have int64
+		tx.Model(&models.Asset{}).Where("room_id = ? AND kind = ? AND status = ?", room.ID, kind, models.AssetActive).Count(&have)
+		for i := int(have); i < n; i++ {
+			asset := models.Asset{ID: uuid.New(), RoomID: room.ID, Kind: kind, Label: fmt.Sprintf("%s %d", kind, i+1), Status: models.AssetActive}
+			if err := tx.Create(&asset).Error; err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := tx.Preload("Assets").First(&room, "id = ?", room.ID).Error; err != nil {
+		return nil, err
+	}
+	return &room, nil
+}
+
+// 🏠 ADMIN — List rooms (with assets), scoped to the admin's block
+func GetRooms(c *fiber.Ctx) error {
+	query := config.DB.Preload("Assets").Order("block asc, number asc")
+	if block := requesterAdminBlock(c); block != "" {
+		query = query.Where("block = ?", block)
+	} else if role, _ := c.Locals("role").(string); role == string(models.Admin) {
+		query = query.Where("1 = 0")
+	} else if block := c.Query("block"); block != "" {
+		query = query.Where("block = ?", strings.ToUpper(block))
+	}
+	if floor := c.Query("floor"); floor != "" {
+		query = query.Where("floor = ?", floor)
+	}
+
+	var rooms []models.Room
+	if err := query.Find(&rooms).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch rooms"})
+	}
+	return c.JSON(fiber.Map{"count": len(rooms), "data": rooms})
+}
+
+// 🏠 ADMIN — Create or update a single room
+func CreateRoom(c *fiber.Ctx) error {
+	var input roomInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
+	}
+	if err := input.normalize(); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	// an admin whose block cannot be resolved may not create rooms anywhere
+	if role, _ := c.Locals("role").(string); role != string(models.ChiefAdmin) && requesterAdminBlock(c) != input.Block {
+		return c.Status(403).JSON(fiber.Map{"error": "Forbidden: admin can only manage rooms in their block"})
+	}
+
+	tx := config.DB.Begin()
+	room, err := upsertRoom(tx, input)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to save room", "details": err.Error()})
+	}
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to save room", "details": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Room saved", "data": room})
+}
+
+// parseRoomsCSV reads rows of block,number,floor,capacity,fixtures where fixtures is a
+// semicolon separated list of asset kinds (e.g. "geyser;fan;ac").
+func parseRoomsCSV(r io.Reader) ([]roomInput, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("CSV file is empty")
+	}
+	cols := map[string]int{}
+	for i, h := range header {
+		cols[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, required := range []string{"block", "number"} {
+		if _, ok := cols[required]; !ok {
+			return nil, fmt.Errorf("CSV header must include %q", required)
+		}
+	}
+	get := func(row []string, name string) string {
+		if i, ok := cols[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	var rooms []roomInput
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		in := roomInput{Block: get(row, "block"), Number: get(row, "number")}
+		if v := get(row, "floor"); v != "" {
+			if in.Floor, err = strconv.Atoi(v); err != nil {
+				return nil, fmt.Errorf("line %d: floor must be a number", line)
+			}
+		}
+		if v := get(row, "capacity"); v != "" {
+			if in.Capacity, err = strconv.Atoi(v); err != nil {
+				return nil, fmt.Errorf("line %d: capacity must be a number", line)
+			}
+		}
+		if v := get(row, "fixtures"); v != "" {
+			in.Fixtures = strings.Split(v, ";")
+		}
+		rooms = append(rooms, in)
+	}
+	return rooms, nil
+}
+
+// 🏠 ADMIN — Bulk import rooms from a CSV upload (field "file") or a JSON {"rooms": [...]} body.
+// The import is all-or-nothing.
+func ImportRooms(c *fiber.Ctx) error {
+	var rooms []roomInput
+	if fh, err := c.FormFile("file"); err == nil {
+		f, err := fh.Open()
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Failed to read upload"})
+		}
+		defer f.Close()
+		if rooms, err = parseRoomsCSV(f); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+	} else {
+		var body struct {
+			Rooms []roomInput `json:"rooms"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
+		}
+		rooms = body.Rooms
+	}
+	if len(rooms) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "No rooms to import"})
+	}
+
+	role, _ := c.Locals("role").(string)
+	adminBlock := requesterAdminBlock(c)
+	if role != string(models.ChiefAdmin) && adminBlock == "" {
+		return c.Status(403).JSON(fiber.Map{"error": "Forbidden: admin has no block assigned"})
+	}
+	for i := range rooms {
+		if err := rooms[i].normalize(); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("room %d: %s", i+1, err.Error())})
+		}
+		if adminBlock != "" && rooms[i].Block != adminBlock {
+			return c.Status(403).JSON(fiber.Map{"error": fmt.Sprintf("room %d: admin can only import rooms in block %s", i+1, adminBlock)})
+		}
+	}
+
+	tx := config.DB.Begin()
+	for i, in := range rooms {
+		if _, err := upsertRoom(tx, in); err != nil {
+			tx.Rollback()
+			return c.Status(500).JSON(fiber.Map{"error": fmt.Sprintf("room %d: failed to import", i+1), "details": err.Error()})
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to import rooms", "details": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Rooms imported", "count": len(rooms)})
+}
+
+// loadScopedRoom fetches the :id room and checks the admin's block.
+func loadScopedRoom(c *fiber.Ctx, id string) (*models.Room, error) {
+	var room models.Room
+	if err := config.DB.First(&room, "id = ?", id).Error; err != nil {
+		return nil, c.Status(404).JSON(fiber.Map{"error": "Room not found"})
+	}
+	if !canAccessStudentBlock(c, room.Block) {
+		return nil, c.Status(403).JSON(fiber.Map{"error": "Forbidden: room is outside your block"})
+	}
+	return &room, nil
+}
+
+// 🔧 ADMIN — Add an asset to a room
+func CreateAsset(c *fiber.Ctx) error {
+	room, errResp := loadScopedRoom(c, c.Params("id"))
+	if room == nil {
+		return errResp
+	}
+	var input struct {
+		Kind     string `json:"kind"`
+		Label    string `json:"label"`
+		SerialNo string `json:"serial_no"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
+	}
+	kind := strings.ToLower(strings.TrimSpace(input.Kind))
+	if kind == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "kind is required"})
+	}
+	asset := models.Asset{ID: uuid.New(), RoomID: room.ID, Kind: kind, Label: strings.TrimSpace(input.Label), SerialNo: strings.TrimSpace(input.SerialNo), Status: models.AssetActive}
+	if asset.Label == "" {
+		asset.Label = kind
+	}
+	if err := config.DB.Create(&asset).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create asset"})
+	}
+	return c.Status(201).JSON(fiber.Map{"message": "Asset created", "data": asset})
+}
+
+// 🔧 ADMIN — Retire or reactivate an asset
+func UpdateAssetStatus(c *fiber.Ctx) error {
+	var asset models.Asset
+	if err := config.DB.First(&asset, "id = ?", c.Params("id")).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Asset not found"})
+	}
+	if room, errResp := loadScopedRoom(c, asset.RoomID.String()); room == nil {
+		return errResp
+	}
+	var input struct {
+		Status models.AssetStatus `json:"status"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
+	}
+	if input.Status != models.AssetActive && input.Status != models.AssetRetired {
+		return c.Status(400).JSON(fiber.Map{"error": "status must be active or retired"})
+	}
+	if err := config.DB.Model(&asset).Update("status", input.Status).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update asset"})
+	}
+	return c.JSON(fiber.Map{"message": "Asset updated", "data": asset})
+}
+
+// 🔧 ADMIN — Repair history of an asset: every complaint that referenced it
+func GetAssetHistory(c *fiber.Ctx) error {
+	var asset models.Asset
+	if err := config.DB.First(&asset, "id = ?", c.Params("id")).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Asset not found"})
+	}
+	room, errResp := loadScopedRoom(c, asset.RoomID.String())
+	if room == nil {
+		return errResp
+	}
+
+	// confidential complaints are left out: tied to a fixture they point at the room's occupant
+	var complaints []models.Complaint
+	if err := config.DB.Preload("Timeline").Where("asset_id = ? AND is_confidential = false", asset.ID).Order("created_at desc").Find(&complaints).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load asset history"})
+	}
+	redactComplaintsForStaff(c, complaints)
+	return c.JSON(fiber.Map{"asset": asset, "room": room, "count": len(complaints), "data": complaints})
+}
+
+// 🔧 ADMIN — Assets ranked by how often they were complained about in the last ?days (default 90)
+func GetFailingAssets(c *fiber.Ctx) error {
+	days := c.QueryInt("days", 90)
+	minCount := c.QueryInt("min", 2)
+
+	query := config.DB.Table("assets").
+		Select("assets.id, assets.kind, assets.label, rooms.block, rooms.number AS room_number, COUNT(complaints.id) AS complaint_count, MAX(complaints.created_at) AS last_complaint_at").
+		Joins("JOIN rooms ON rooms.id = assets.room_id").
+		Joins("JOIN complaints ON complaints.asset_id = assets.id AND complaints.deleted_at IS NULL AND complaints.is_confidential = false").
+		Where("complaints.created_at >= NOW() - make_interval(days => ?)", days).
+		Group("assets.id, rooms.block, rooms.number").
+		Having("COUNT(complaints.id) >= ?", minCount).
+		Order("complaint_count desc")
+	if block := requesterAdminBlock(c); block != "" {
+		query = query.Where("rooms.block = ?", block)
+	} else if role, _ := c.Locals("role").(string); role == string(models.Admin) {
+		query = query.Where("1 = 0")
+	}
+
+	var rows []map[string]interface{}
+	if err := query.Find(&rows).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to compute failing assets"})
+	}
+	return c.JSON(fiber.Map{"days": days, "min": minCount, "count": len(rows), "data": rows})
+}
+
+// 🧑‍🎓 STUDENT — Room and fixtures of the logged-in student, for picking what is broken
+func GetMyRoom(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	var sm models.StudentModel
+	if err := config.DB.Where("user_id = ?", userID).First(&sm).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Student record not found"})
+	}
+	var room models.Room
+	if err := config.DB.Preload("Assets", "status = ?", models.AssetActive).
+		Where("block = ? AND number = ?", sm.Block, sm.RoomNo).First(&room).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Room is not registered yet"})
+	}
+	return c.JSON(fiber.Map{"data": room})
+}
+
+// linkComplaintRoom validates and sets the optional room and asset on a new complaint. The room
+// must be in the student's block and the asset must be in that room. With neither given, the
+// student's registered room is linked when it exists, unless the complaint is confidential.
+func linkComplaintRoom(complaint *models.Complaint, sm models.StudentModel, roomID, assetID string) error {
+	var room models.Room
+	switch {
+	case roomID != "":
+		if err := config.DB.First(&room, "id = ?", roomID).Error; err != nil {
+			return errors.New("room not found")
+		}
+		if room.Block != sm.Block {
+			return errors.New("room is not in your block")
+		}
+	case assetID == "":
+		if !complaint.IsConfidential && sm.RoomNo != "" && config.DB.Where("block = ? AND number = ?", sm.Block, sm.RoomNo).First(&room).Error == nil {
+			complaint.RoomID = &room.ID
+		}
+		return nil
+	}
+
+	if assetID != "" {
+		var asset models.Asset
+		if err := config.DB.First(&asset, "id = ?", assetID).Error; err != nil {
+			return errors.New("asset not found")
+		}
+		if room.ID == uuid.Nil {
+			if err := config.DB.First(&room, "id = ?", asset.RoomID).Error; err != nil || room.Block != sm.Block {
+				return errors.New("asset is not in your block")
+			}
+		} else if asset.RoomID != room.ID {
+			return errors.New("asset does not belong to the given room")
+		}
+		complaint.AssetID = &asset.ID
+	}
+	complaint.RoomID = &room.ID
+	return nil
+}
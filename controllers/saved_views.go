@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/aditisaxena259/mental-health-be/config"
+	"github.com/aditisaxena259/mental-health-be/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// loadVisibleView loads a saved view the requester owns, or one shared within their block.
+func loadVisibleView(c *fiber.Ctx, id string) (*models.SavedView, error) {
+	userID, _ := c.Locals("user_id").(string)
+	var view models.SavedView
+	if err := config.DB.First(&view, "id = ?", id).Error; err != nil {
+		return nil, c.Status(404).JSON(fiber.Map{"error": "Saved view not found"})
+	}
+	if view.OwnerID.String() != userID && !(view.Shared && view.Block == requesterAdminBlock(c)) {
+		return nil, c.Status(404).JSON(fiber.Map{"error": "Saved view not found"})
+	}
+	return &view, nil
+}
+
+// loadOwnView loads a saved view owned by the requester, for edits and deletes.
+func loadOwnView(c *fiber.Ctx) (*models.SavedView, error) {
+	userID, _ := c.Locals("user_id").(string)
+	var view models.SavedView
+	if err := config.DB.First(&view, "id = ? AND owner_id = ?", c.Params("id"), userID).Error; err != nil {
+		return nil, c.Status(404).JSON(fiber.Map{"error": "Saved view not found"})
+	}
+	return &view, nil
+}
+
+type savedViewInput struct {
+	Name   *string                 `json:"name"`
+	Filter *models.ComplaintFilter `json:"filter"`
+	Sort   *string                 `json:"sort"`
+	Shared *bool                   `json:"shared"`
+}
+
+func (in savedViewInput) apply(view *models.SavedView) error {
+	if in.Name != nil {
+		view.Name = strings.TrimSpace(*in.Name)
+	}
+	if in.Filter != nil {
+		view.Filter = *in.Filter
+	}
+	if in.Sort != nil {
+		if _, ok := complaintSorts[*in.Sort]; !ok && *in.Sort != "" {
+			return errors.New("unknown sort: " + *in.Sort)
+		}
+		view.Sort = *in.Sort
+	}
+	if in.Shared != nil {
+		view.Shared = *in.Shared
+	}
+	if view.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+// 👁️ ADMIN — List own saved views and views shared within the block
+func GetSavedViews(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	var views []models.SavedView
+	if err := config.DB.
+		Where("owner_id = ? OR (shared = ? AND block = ?)", userID, true, requesterAdminBlock(c)).
+		Order("name asc").Find(&views).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch saved views"})
+	}
+	return c.JSON(fiber.Map{"count": len(views), "data": views})
+}
+
+// 👁️ ADMIN — Save a named filter/sort combination
+func CreateSavedView(c *fiber.Ctx) error {
+	var input savedViewInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
+	}
+	userID, _ := c.Locals("user_id").(string)
+	ownerID, err := uuid.Parse(userID)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized: missing user ID"})
+	}
+	view := models.SavedView{ID: uuid.New(), OwnerID: ownerID, Block: requesterAdminBlock(c)}
+	if err := input.apply(&view); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := config.DB.Create(&view).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to save view", "details": err.Error()})
+	}
+	return c.Status(201).JSON(fiber.Map{"message": "View saved", "data": view})
+}
+
+// 👁️ ADMIN — Update an own saved view
+func UpdateSavedView(c *fiber.Ctx) error {
+	var input savedViewInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
+	}
+	view, errResp := loadOwnView(c)
+	if view == nil {
+		return errResp
+	}
+	if err := input.apply(view); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := config.DB.Save(view).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update view"})
+	}
+	return c.JSON(fiber.Map{"message": "View updated", "data": view})
+}
+
+// 👁️ ADMIN — Delete an own saved view
+func DeleteSavedView(c *fiber.Ctx) error {
+	view, errResp := loadOwnView(c)
+	if view == nil {
+		return errResp
+	}
+	if err := config.DB.Delete(view).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete view"})
+	}
+	return c.JSON(fiber.Map{"message": "View deleted"})
+}
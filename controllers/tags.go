@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/aditisaxena259/mental-health-be/config"
+	"github.com/aditisaxena259/mental-health-be/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const maxTagLength = 40
+
+// normalizeTagName lowercases a tag and collapses whitespace so "Needs  Vendor" and
+// "needs vendor" are the same tag.
+func normalizeTagName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// findOrCreateTag returns the block's tag with the given name, creating it when missing.
+func findOrCreateTag(tx *gorm.DB, block, name string, creatorID uuid.UUID) (*models.Tag, error) {
+	name = normalizeTagName(name)
+	if name == "" {
+		return nil, errors.New("tag name cannot be empty")
+	}
+	if len(name) > maxTagLength {
+		return nil, errors.New("tag name is too long")
+	}
+	if strings.TrimSpace(block) == "" {
+		return nil, errors.New("complaint has no block to scope the tag to")
+	}
+	tag := models.Tag{Block: block, Name: name}
+	if creatorID != uuid.Nil {
+		tag.CreatedBy = &creatorID
+	}
+	if err := tx.Where("block = ? AND name = ?", block, name).FirstOrCreate(&tag).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// 🏷️ ADMIN — Tags of the admin's block with usage counts (chief admins may pass ?block=)
+func GetTags(c *fiber.Ctx) error {
+	block := requesterAdminBlock(c)
+	if block == "" {
+		block = strings.ToUpper(c.Query("block"))
+	}
+	query := config.DB.Table("tags").
+		Select("tags.id, tags.block, tags.name, COUNT(complaint_tags.complaint_id) AS usage").
+		Joins("LEFT JOIN complaint_tags ON complaint_tags.tag_id = tags.id").
+		Group("tags.id").
+		Order("usage desc, tags.name asc")
+	if block != "" {
+		query = query.Where("tags.block = ?", block)
+	}
+	var rows []struct {
+		ID    uuid.UUID `json:"id"`
+		Block string    `json:"block"`
+		Name  string    `json:"name"`
+		Usage int64     `json:"usage"`
+	}
+	if err := query.Scan(&rows).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch tags"})
+	}
+	return c.JSON(fiber.Map{"count": len(rows), "data": rows})
+}
+
+// 🏷️ ADMIN — Add/remove tags on a single complaint
+func UpdateComplaintTags(c *fiber.Ctx) error {
+	var input struct {
+		Add    []string `json:"add"`
+		Remove []string `json:"remove"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
+	}
+	var complaint models.Complaint
+	if err := config.DB.Preload("Student").Preload("Tags").First(&complaint, "id = ?", c.Params("id")).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Complaint not found"})
+	}
+	if !canAccessStudentBlock(c, complaint.Student.Block) {
+		return c.Status(403).JSON(fiber.Map{"error": "Forbidden: admin not authorized for this complaint"})
+	}
+
+	role, _ := c.Locals("role").(string)
+	requesterID, _ := c.Locals("user_id").(string)
+	actorID, _ := uuid.Parse(requesterID)
+
+	tx := config.DB.Begin()
+	if _, err := applyTagChanges(tx, &complaint, input.Add, input.Remove, complaintActor{ID: actorID, Role: role}); err != nil {
+		tx.Rollback()
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update tags"})
+	}
+	config.DB.Model(&complaint).Association("Tags").Find(&complaint.Tags)
+	return c.JSON(fiber.Map{"message": "Tags updated", "data": complaint.Tags})
+}
+
+// 🏷️ ADMIN — Delete a tag from the block entirely
+func DeleteTag(c *fiber.Ctx) error {
+	var tag models.Tag
+	if err := config.DB.First(&tag, "id = ?", c.Params("id")).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Tag not found"})
+	}
+	if !canAccessStudentBlock(c, tag.Block) {
+		return c.Status(403).JSON(fiber.Map{"error": "Forbidden: tag belongs to another block"})
+	}
+	tx := config.DB.Begin()
+	if err := tx.Exec("DELETE FROM complaint_tags WHERE tag_id = ?", tag.ID).Error; err != nil {
+		tx.Rollback()
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete tag"})
+	}
+	if err := tx.Delete(&tag).Error; err != nil {
+		tx.Rollback()
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete tag"})
+	}
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete tag"})
+	}
+	return c.JSON(fiber.Map{"message": "Tag deleted"})
+}
@@ -1,51 +1,155 @@
 package controllers
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/aditisaxena259/mental-health-be/config"
+	"github.com/aditisaxena259/mental-health-be/jobs"
 	"github.com/aditisaxena259/mental-health-be/models"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+// newTimelineEntry builds a timeline entry authored by the given user.
+// authorID may be uuid.Nil for system-generated entries.
+func newTimelineEntry(complaintID, authorID uuid.UUID, role string, visibility models.TimelineVisibility, message string) models.TimelineEntry {
+	entry := models.TimelineEntry{
+		ID:          uuid.New(),
+		ComplaintID: complaintID,
+		Author:      role,
+		Visibility:  visibility,
+		EventType:   models.EventComment,
+		Message:     message,
+		Timestamp:   time.Now(),
+	}
+	if authorID != uuid.Nil {
+		id := authorID
+		entry.AuthorID = &id
+	}
+	return entry
+}
+
+// newChangeEntry builds a public, typed timeline event recording a change from oldValue to newValue.
+// Empty values are stored as NULL.
+func newChangeEntry(complaintID, authorID uuid.UUID, role string, eventType models.TimelineEventType, oldValue, newValue, message string) models.TimelineEntry {
+	entry := newTimelineEntry(complaintID, authorID, role, models.TimelinePublic, message)
+	entry.EventType = eventType
+	if oldValue != "" {
+		entry.OldValue = &oldValue
+	}
+	if newValue != "" {
+		entry.NewValue = &newValue
+	}
+	return entry
+}
+
+// publicTimeline is a Preload scope that hides internal staff notes.
+func publicTimeline(db *gorm.DB) *gorm.DB {
+	return db.Where("visibility = ?", models.TimelinePublic).Order("timestamp asc")
+}
+
+// loadTimelineComplaint loads the complaint behind a timeline request and checks that the
+// requester may see it: students only their own, admins only their block.
+func loadTimelineComplaint(c *fiber.Ctx) (*models.Complaint, error) {
+	compUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, c.Status(400).JSON(fiber.Map{"error": "Invalid complaint id"})
+	}
+	var complaint models.Complaint
+	if err := config.DB.Preload("Student").First(&complaint, "id = ?", compUUID).Error; err != nil {
+		return nil, c.Status(404).JSON(fiber.Map{"error": "Complaint not found"})
+	}
+	role, _ := c.Locals("role").(string)
+	userID, _ := c.Locals("user_id").(string)
+	if role == string(models.Student) {
+		if complaint.UserID.String() != userID {
+			return nil, c.Status(404).JSON(fiber.Map{"error": "Complaint not found"})
+		}
+	} else if !canAccessStudentBlock(c, complaint.Student.Block) {
+		return nil, c.Status(403).JSON(fiber.Map{"error": "Forbidden: not authorized for this complaint"})
+	}
+	return &complaint, nil
+}
+
 // POST /complaints/:id/timeline
 func AddTimelineEntry(c *fiber.Ctx) error {
-	complaintID := c.Params("id")
-	userID := c.Locals("user_id").(string)
-	role := c.Locals("role").(string)
+	userID, _ := c.Locals("user_id").(string)
+	role, _ := c.Locals("role").(string)
 
 	var input struct {
-		Message string `json:"message"`
+		Message    string                    `json:"message"`
+		Visibility models.TimelineVisibility `json:"visibility"`
 	}
 
 	if err := c.BodyParser(&input); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
 	}
+	if input.Message == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "message is required"})
+	}
 
-	entry := models.TimelineEntry{
-		ID:          uuid.New(),
-		ComplaintID: uuid.MustParse(complaintID),
-		Author:      role + ":" + userID,
-		Message:     input.Message,
-		Timestamp:   time.Now(),
+	complaint, errResp := loadTimelineComplaint(c)
+	if complaint == nil {
+		return errResp
+	}
+
+	// Students can only post public entries; staff default to public unless marked internal
+	visibility := models.TimelinePublic
+	if input.Visibility != "" {
+		if input.Visibility != models.TimelinePublic && input.Visibility != models.TimelineInternal {
+			return c.Status(400).JSON(fiber.Map{"error": "visibility must be public or internal"})
+		}
+		if role == string(models.Student) && input.Visibility == models.TimelineInternal {
+			return c.Status(403).JSON(fiber.Map{"error": "Students cannot post internal notes"})
+		}
+		visibility = input.Visibility
 	}
 
-	if err := config.DB.Create(&entry).Error; err != nil {
+	authorID, err := uuid.Parse(userID)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized: missing user ID"})
+	}
+	entry := newTimelineEntry(complaint.ID, authorID, role, visibility, input.Message)
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&entry).Error; err != nil {
+			return err
+		}
+		// followers hear about public updates; internal staff notes stay quiet
+		if visibility != models.TimelinePublic {
+			return nil
+		}
+		return enqueueWatcherNotification(tx, complaint.ID, "New Update on Followed Complaint",
+			fmt.Sprintf("New comment on %q", complaint.Title), "info", authorID, complaint.UserID)
+	})
+	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to add timeline entry"})
 	}
+	jobs.WakeOutbox()
 
 	return c.JSON(entry)
 }
 
 // GET /complaints/:id/timeline
 func GetTimeline(c *fiber.Ctx) error {
-	id := c.Params("id")
+	complaint, errResp := loadTimelineComplaint(c)
+	if complaint == nil {
+		return errResp
+	}
+
+	query := config.DB.Where("complaint_id = ?", complaint.ID)
+	if role, _ := c.Locals("role").(string); role == string(models.Student) {
+		query = query.Where("visibility = ?", models.TimelinePublic)
+	}
+
 	var timeline []models.TimelineEntry
-	if err := config.DB.Where("complaint_id = ?", id).
-		Order("timestamp asc").
-		Find(&timeline).Error; err != nil {
+	if err := query.Order("timestamp asc").Find(&timeline).Error; err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to load timeline"})
 	}
+	if role, _ := c.Locals("role").(string); complaint.IsConfidential && role != string(models.Student) {
+		redactTimeline(timeline)
+	}
 	return c.JSON(timeline)
 }
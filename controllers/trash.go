@@ -0,0 +1,202 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/aditisaxena259/mental-health-be/config"
+	"github.com/aditisaxena259/mental-health-be/jobs"
+	"github.com/aditisaxena259/mental-health-be/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// trashedItem wraps a trashed complaint or apology with the moment it will be purged.
+type trashedItem struct {
+	Item            interface{} `json:"item"`
+	DeletedAt       time.Time   `json:"deleted_at"`
+	RestorableUntil time.Time   `json:"restorable_until"`
+}
+
+// loadTrashedComplaint loads a soft-deleted :id complaint within the requester's block.
+func loadTrashedComplaint(c *fiber.Ctx) (*models.Complaint, error) {
+	compUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, c.Status(400).JSON(fiber.Map{"error": "Invalid complaint id"})
+	}
+	var complaint models.Complaint
+	if err := config.DB.Unscoped().Preload("Student").
+		Where("deleted_at IS NOT NULL").First(&complaint, "id = ?", compUUID).Error; err != nil {
+		return nil, c.Status(404).JSON(fiber.Map{"error": "Complaint not found in trash"})
+	}
+	if !canAccessStudentBlock(c, complaint.Student.Block) {
+		return nil, c.Status(403).JSON(fiber.Map{"error": "Forbidden: not authorized for this complaint"})
+	}
+	return &complaint, nil
+}
+
+// loadTrashedApology loads a soft-deleted :id apology within the requester's block.
+func loadTrashedApology(c *fiber.Ctx) (*models.Apology, error) {
+	apologyUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, c.Status(400).JSON(fiber.Map{"error": "Invalid apology id"})
+	}
+	var apology models.Apology
+	if err := config.DB.Unscoped().Preload("Student").
+		Where("deleted_at IS NOT NULL").First(&apology, "id = ?", apologyUUID).Error; err != nil {
+		return nil, c.Status(404).JSON(fiber.Map{"error": "Apology not found in trash"})
+	}
+	if !canAccessStudentBlock(c, apology.Student.Block) {
+		return nil, c.Status(403).JSON(fiber.Map{"error": "Forbidden: not authorized for this apology"})
+	}
+	return &apology, nil
+}
+
+// 🗑️ ADMIN — Complaints in the trash, newest deletion first
+func GetTrashedComplaints(c *fiber.Ctx) error {
+	var complaints []models.Complaint
+	query := config.DB.Unscoped().Preload("User").Preload("Student").Preload("Attachments").
+		Where("complaints.deleted_at IS NOT NULL").
+		Scopes(complaintFilterFromQuery(c).scope(c))
+	if err := query.Order("complaints.deleted_at desc").Find(&complaints).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch trash"})
+	}
+	redactComplaintsForStaff(c, complaints)
+
+	cfg := jobs.TrashConfigFromEnv()
+	items := make([]trashedItem, 0, len(complaints))
+	for i := range complaints {
+		deletedAt := complaints[i].DeletedAt.Time
+		items = append(items, trashedItem{Item: complaints[i], DeletedAt: deletedAt, RestorableUntil: cfg.RestorableUntil(deletedAt)})
+	}
+	return c.JSON(fiber.Map{"count": len(items), "retention_days": cfg.RetentionDays, "data": items})
+}
+
+// ♻️ ADMIN — Restore a trashed complaint
+func RestoreComplaint(c *fiber.Ctx) error {
+	complaint, errResp := loadTrashedComplaint(c)
+	if complaint == nil {
+		return errResp
+	}
+	if time.Now().After(jobs.TrashConfigFromEnv().RestorableUntil(complaint.DeletedAt.Time)) {
+		return c.Status(410).JSON(fiber.Map{"error": "Retention window has passed; complaint is awaiting purge"})
+	}
+	role, _ := c.Locals("role").(string)
+	userID, _ := c.Locals("user_id").(string)
+	actorID, _ := uuid.Parse(userID)
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(complaint).Updates(map[string]interface{}{"deleted_at": nil, "deleted_by_id": nil}).Error; err != nil {
+			return err
+		}
+		entry := newTimelineEntry(complaint.ID, actorID, role, models.TimelineInternal, "Complaint restored from trash")
+		return tx.Create(&entry).Error
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to restore complaint"})
+	}
+	return c.JSON(fiber.Map{"message": "Complaint restored"})
+}
+
+// 🔥 CHIEF ADMIN — Permanently delete a trashed complaint before the retention window ends
+func PurgeTrashedComplaint(c *fiber.Ctx) error {
+	complaint, errResp := loadTrashedComplaint(c)
+	if complaint == nil {
+		return errResp
+	}
+	if err := jobs.PurgeComplaint(complaint.ID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to purge complaint", "details": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Complaint permanently deleted"})
+}
+
+// 🧑‍💼 ADMIN — Delete Apology (moves it to the trash)
+func DeleteApology(c *fiber.Ctx) error {
+	apologyUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid apology id"})
+	}
+	var apology models.Apology
+	if err := config.DB.Preload("Student").First(&apology, "id = ?", apologyUUID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Apology not found"})
+	}
+	if !canAccessStudentBlock(c, apology.Student.Block) {
+		return c.Status(403).JSON(fiber.Map{"error": "Forbidden: admin not authorized to delete this apology"})
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	deleterID, _ := uuid.Parse(userID)
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&apology).Update("deleted_by_id", deleterID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&apology).Error; err != nil {
+			return err
+		}
+		event := newApologyEvent(c, apology.ID, models.ApologyEventDeleted, apology.Status, apology.Status, "Moved to trash")
+		return tx.Create(&event).Error
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete apology", "details": err.Error()})
+	}
+	return c.JSON(fiber.Map{
+		"message":          "Apology moved to trash",
+		"restorable_until": jobs.TrashConfigFromEnv().RestorableUntil(time.Now()),
+	})
+}
+
+// 🗑️ ADMIN — Apologies in the trash, newest deletion first
+func GetTrashedApologies(c *fiber.Ctx) error {
+	var apologies []models.Apology
+	query := config.DB.Unscoped().Preload("Student.User").Preload("Attachments").
+		Where("apologies.deleted_at IS NOT NULL").
+		Scopes(apologyFilterScope(c))
+	if err := query.Order("apologies.deleted_at desc").Find(&apologies).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch trash"})
+	}
+
+	cfg := jobs.TrashConfigFromEnv()
+	items := make([]trashedItem, 0, len(apologies))
+	for i := range apologies {
+		deletedAt := apologies[i].DeletedAt.Time
+		items = append(items, trashedItem{Item: apologies[i], DeletedAt: deletedAt, RestorableUntil: cfg.RestorableUntil(deletedAt)})
+	}
+	return c.JSON(fiber.Map{"count": len(items), "retention_days": cfg.RetentionDays, "data": items})
+}
+
+// ♻️ ADMIN — Restore a trashed apology
+func RestoreApology(c *fiber.Ctx) error {
+	apology, errResp := loadTrashedApology(c)
+	if apology == nil {
+		return errResp
+	}
+	if time.Now().After(jobs.TrashConfigFromEnv().RestorableUntil(apology.DeletedAt.Time)) {
+		return c.Status(410).JSON(fiber.Map{"error": "Retention window has passed; apology is awaiting purge"})
+	}
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(apology).Updates(map[string]interface{}{"deleted_at": nil, "deleted_by_id": nil}).Error; err != nil {
+			return err
+		}
+		event := newApologyEvent(c, apology.ID, models.ApologyEventRestored, apology.Status, apology.Status, "Restored from trash")
+		return tx.Create(&event).Error
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to restore apology"})
+	}
+	return c.JSON(fiber.Map{"message": "Apology restored"})
+}
+
+// 🔥 CHIEF ADMIN — Permanently delete a trashed apology before the retention window ends
+func PurgeTrashedApology(c *fiber.Ctx) error {
+	apology, errResp := loadTrashedApology(c)
+	if apology == nil {
+		return errResp
+	}
+	userID, _ := c.Locals("user_id").(string)
+	actorID, _ := uuid.Parse(userID)
+	role, _ := c.Locals("role").(string)
+	if err := jobs.PurgeApology(apology.ID, &actorID, role); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to purge apology", "details": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Apology permanently deleted"})
+}
@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Upload limits for complaint and apology attachments.
+// Env: ATTACHMENT_MAX_MB (per file, default 5), ATTACHMENT_MAX_FILES (per request, default 5).
+func attachmentMaxBytes() int64 {
+	if v, err := strconv.Atoi(os.Getenv("ATTACHMENT_MAX_MB")); err == nil && v > 0 {
+		return int64(v) << 20
+	}
+	return 5 << 20
+}
+
+func attachmentMaxFiles() int {
+	if v, err := strconv.Atoi(os.Getenv("ATTACHMENT_MAX_FILES")); err == nil && v > 0 {
+		return v
+	}
+	return 5
+}
+
+// formOverhead leaves room for the text fields and multipart framing around the attachments.
+const formOverhead = 1 << 20
+
+// RequestBodyLimit is the largest request body the server should accept: a full set of
+// attachments at the maximum size plus the rest of the form.
+func RequestBodyLimit() int {
+	return int(attachmentMaxBytes())*attachmentMaxFiles() + formOverhead
+}
+
+// Content types accepted per attachment kind, detected from the file content rather than
+// the client's filename or header.
+var (
+	complaintAttachmentTypes = []string{"image/jpeg"}
+	// scanned signed letters come as PDFs
+	apologyAttachmentTypes = []string{"image/jpeg", "application/pdf"}
+)
+
+var attachmentTypeInfo = map[string]struct{ label, ext string }{
+	"image/jpeg":      {"JPEG", ".jpg"},
+	"application/pdf": {"PDF", ".pdf"},
+}
+
+// sniffContentType detects a file's type from its first 512 bytes.
+func sniffContentType(fh *multipart.FileHeader) string {
+	f, err := fh.Open()
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	buf := make([]byte, 512)
+	n, _ := f.Read(buf)
+	if n == 0 {
+		return ""
+	}
+	return strings.TrimSpace(strings.Split(http.DetectContentType(buf[:n]), ";")[0])
+}
+
+// checkAttachments validates every file before anything is stored and returns the detected
+// content type of each. The error message is meant for the client.
+func checkAttachments(files []*multipart.FileHeader, allowed []string) ([]string, error) {
+	if len(files) > attachmentMaxFiles() {
+		return nil, fmt.Errorf("At most %d attachments are allowed per upload", attachmentMaxFiles())
+	}
+	labels := []string{}
+	for _, t := range allowed {
+		labels = append(labels, attachmentTypeInfo[t].label)
+	}
+	types := make([]string, len(files))
+	for i, fh := range files {
+		if fh == nil {
+			continue
+		}
+		if fh.Size > attachmentMaxBytes() {
+			return nil, fmt.Errorf("%s is larger than %d MB", sanitizeFilename(fh.Filename, ""), attachmentMaxBytes()>>20)
+		}
+		ct := sniffContentType(fh)
+		ok := false
+		for _, t := range allowed {
+			if ct == t {
+				ok = true
+			}
+		}
+		if !ok {
+			return nil, fmt.Errorf("Only %s attachments are allowed", strings.Join(labels, " or "))
+		}
+		types[i] = ct
+	}
+	return types, nil
+}
+
+var unsafeFilenameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// sanitizeFilename reduces a client-supplied name to a safe base name and, when contentType is
+// known, gives it the matching extension.
+func sanitizeFilename(name, contentType string) string {
+	name = filepath.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	name = strings.Trim(unsafeFilenameChars.ReplaceAllString(name, "_"), "._-")
+	if len(name) > 80 {
+		name = name[:80]
+	}
+	if name == "" {
+		name = "attachment"
+	}
+	if info, ok := attachmentTypeInfo[contentType]; ok {
+		return name + info.ext
+	}
+	return name
+}
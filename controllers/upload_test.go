@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"bytes"
+	"mime/multipart"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := []struct {
+		name, contentType, want string
+	}{
+		{"photo.jpg", "image/jpeg", "photo.jpg"},
+		{"scan.JPEG", "application/pdf", "scan.pdf"},
+		{"../../etc/passwd", "image/jpeg", "passwd.jpg"},
+		{`C:\Users\me\broken tap.jpg`, "image/jpeg", "broken_tap.jpg"},
+		{"my letter (final).pdf", "application/pdf", "my_letter_final.pdf"},
+		{".hidden", "image/jpeg", "attachment.jpg"},
+		{"", "image/jpeg", "attachment.jpg"},
+		{"résumé.jpg", "", "r_sum"},
+		{strings.Repeat("a", 100) + ".jpg", "image/jpeg", strings.Repeat("a", 80) + ".jpg"},
+	}
+	for _, tt := range tests {
+		if got := sanitizeFilename(tt.name, tt.contentType); got != tt.want {
+			t.Errorf("sanitizeFilename(%q, %q) = %q, want %q", tt.name, tt.contentType, got, tt.want)
+		}
+	}
+}
+
+var (
+	jpegBytes = []byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00")
+	pdfBytes  = []byte("%PDF-1.4\n%test")
+	pngBytes  = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+)
+
+// uploadedFiles builds the file headers a multipart request with the given files would yield.
+func uploadedFiles(t *testing.T, files map[string][]byte) []*multipart.FileHeader {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for name, content := range files {
+		part, err := w.CreateFormFile("attachments", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write(content)
+	}
+	w.Close()
+	form, err := multipart.NewReader(&body, w.Boundary()).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return form.File["attachments"]
+}
+
+func TestCheckAttachments(t *testing.T) {
+	t.Setenv("ATTACHMENT_MAX_MB", "1")
+	t.Setenv("ATTACHMENT_MAX_FILES", "2")
+	tests := []struct {
+		name    string
+		files   map[string][]byte
+		allowed []string
+		want    []string
+		wantErr string
+	}{
+		{name: "no files", allowed: complaintAttachmentTypes, want: []string{}},
+		{name: "jpeg complaint photo", files: map[string][]byte{"a.jpg": jpegBytes}, allowed: complaintAttachmentTypes, want: []string{"image/jpeg"}},
+		{name: "pdf apology letter", files: map[string][]byte{"letter.pdf": pdfBytes}, allowed: apologyAttachmentTypes, want: []string{"application/pdf"}},
+		{name: "pdf on a complaint", files: map[string][]byte{"letter.pdf": pdfBytes}, allowed: complaintAttachmentTypes, wantErr: "Only JPEG attachments are allowed"},
+		{name: "png renamed to jpg", files: map[string][]byte{"a.jpg": pngBytes}, allowed: apologyAttachmentTypes, wantErr: "Only JPEG or PDF attachments are allowed"},
+		{name: "too large", files: map[string][]byte{"big.jpg": append(append([]byte{}, jpegBytes...), make([]byte, 1<<20)...)}, allowed: complaintAttachmentTypes, wantErr: "big is larger than 1 MB"},
+		{name: "too many", files: map[string][]byte{"a.jpg": jpegBytes, "b.jpg": jpegBytes, "c.jpg": jpegBytes}, allowed: complaintAttachmentTypes, wantErr: "At most 2 attachments are allowed per upload"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := checkAttachments(uploadedFiles(t, tt.files), tt.allowed)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("content types = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestBodyLimit(t *testing.T) {
+	t.Setenv("ATTACHMENT_MAX_MB", "")
+	t.Setenv("ATTACHMENT_MAX_FILES", "")
+	if got, want := RequestBodyLimit(), 26<<20; got != want {
+		t.Errorf("RequestBodyLimit() = %d, want %d", got, want)
+	}
+	t.Setenv("ATTACHMENT_MAX_MB", "2")
+	t.Setenv("ATTACHMENT_MAX_FILES", "3")
+	if got, want := RequestBodyLimit(), 7<<20; got != want {
+		t.Errorf("RequestBodyLimit() = %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,208 @@
+package controllers
+
+import (
+	"github.com/aditisaxena259/mental-health-be/config"
+	"github.com/aditisaxena259/mental-health-be/jobs"
+	"github.com/aditisaxena259/mental-health-be/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// addWatcher makes userID follow the complaint. Existing watchers keep their original reason.
+func addWatcher(tx *gorm.DB, complaintID, userID uuid.UUID, reason models.WatchReason) error {
+	w := models.ComplaintWatcher{ComplaintID: complaintID, UserID: userID, Reason: reason}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&w).Error
+}
+
+// enqueueWatcherNotification queues a notification in tx to every watcher of the complaint,
+// skipping the user ids in exclude (typically the actor and the filing student, who is notified separately).
+func enqueueWatcherNotification(tx *gorm.DB, complaintID uuid.UUID, title, message, ntype string, exclude ...uuid.UUID) error {
+	var watchers []models.ComplaintWatcher
+	if err := tx.Where("complaint_id = ?", complaintID).Find(&watchers).Error; err != nil {
+		return err
+	}
+	skip := map[uuid.UUID]bool{}
+	for _, id := range exclude {
+		skip[id] = true
+	}
+	recipients := []uuid.UUID{}
+	for _, w := range watchers {
+		if !skip[w.UserID] {
+			recipients = append(recipients, w.UserID)
+		}
+	}
+	if len(recipients) == 0 {
+		return nil
+	}
+	return jobs.EnqueueNotification(tx, models.NotificationFanout{
+		UserIDs:     recipients,
+		Title:       title,
+		Message:     message,
+		Type:        ntype,
+		RelatedID:   &complaintID,
+		RelatedType: "complaint",
+	})
+}
+
+// loadFollowableComplaint loads the :id complaint and checks the requester may follow it:
+// staff within their block, and students for non-confidential complaints in their own block.
+func loadFollowableComplaint(c *fiber.Ctx) (*models.Complaint, error) {
+	compUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, c.Status(400).JSON(fiber.Map{"error": "Invalid complaint id"})
+	}
+	var complaint models.Complaint
+	if err := config.DB.Preload("Student").First(&complaint, "id = ?", compUUID).Error; err != nil {
+		return nil, c.Status(404).JSON(fiber.Map{"error": "Complaint not found"})
+	}
+	role, _ := c.Locals("role").(string)
+	userID, _ := c.Locals("user_id").(string)
+	if role != string(models.Student) {
+		if !canAccessStudentBlock(c, complaint.Student.Block) {
+			return nil, c.Status(403).JSON(fiber.Map{"error": "Forbidden: not authorized for this complaint"})
+		}
+		return &complaint, nil
+	}
+	if complaint.UserID.String() == userID {
+		return nil, c.Status(400).JSON(fiber.Map{"error": "You are already notified about your own complaint"})
+	}
+	var sm models.StudentModel
+	if complaint.IsConfidential || config.DB.Where("user_id = ?", userID).First(&sm).Error != nil || sm.Block != complaint.Student.Block {
+		return nil, c.Status(404).JSON(fiber.Map{"error": "Complaint not found"})
+	}
+	return &complaint, nil
+}
+
+// 👀 ALL USERS — Follow a complaint
+func FollowComplaint(c *fiber.Ctx) error {
+	complaint, errResp := loadFollowableComplaint(c)
+	if complaint == nil {
+		return errResp
+	}
+	userID, _ := c.Locals("user_id").(string)
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized: missing user ID"})
+	}
+	if err := addWatcher(config.DB, complaint.ID, uid, models.WatchFollow); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to follow complaint"})
+	}
+	return c.JSON(fiber.Map{"message": "Following complaint"})
+}
+
+// 👀 ALL USERS — Stop following a complaint
+func UnfollowComplaint(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	if err := config.DB.Where("complaint_id = ? AND user_id = ?", c.Params("id"), userID).Delete(&models.ComplaintWatcher{}).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to unfollow complaint"})
+	}
+	return c.JSON(fiber.Map{"message": "Unfollowed complaint"})
+}
+
+// ➕ STUDENT — "+1" an open complaint in the same block: follow it and raise its impact score
+func PlusOneComplaint(c *fiber.Ctx) error {
+	complaint, errResp := loadFollowableComplaint(c)
+	if complaint == nil {
+		return errResp
+	}
+	if complaint.Status != models.Open && complaint.Status != models.InProgress {
+		return c.Status(409).JSON(fiber.Map{"error": "Only open complaints can be +1'd"})
+	}
+	userID, _ := c.Locals("user_id").(string)
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized: missing user ID"})
+	}
+
+	tx := config.DB.Begin()
+	var existing models.ComplaintWatcher
+	if tx.Where("complaint_id = ? AND user_id = ?", complaint.ID, uid).First(&existing).Error == nil {
+		if existing.Reason == models.WatchPlusOne {
+			tx.Rollback()
+			return c.Status(409).JSON(fiber.Map{"error": "You have already +1'd this complaint"})
+		}
+		if err := tx.Model(&existing).Update("reason", models.WatchPlusOne).Error; err != nil {
+			tx.Rollback()
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to +1 complaint"})
+		}
+	} else if err := addWatcher(tx, complaint.ID, uid, models.WatchPlusOne); err != nil {
+		tx.Rollback()
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to +1 complaint"})
+	}
+	if err := tx.Model(complaint).UpdateColumn("impact_score", gorm.Expr("impact_score + 1")).Error; err != nil {
+		tx.Rollback()
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to +1 complaint"})
+	}
+	entry := newChangeEntry(complaint.ID, uid, string(models.Student), models.EventComment, "", "", "Another student reported the same issue (+1)")
+	if err := tx.Create(&entry).Error; err != nil {
+		tx.Rollback()
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to add timeline entry"})
+	}
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to +1 complaint"})
+	}
+
+	var score int
+	config.DB.Model(&models.Complaint{}).Where("id = ?", complaint.ID).Select("impact_score").Scan(&score)
+	return c.JSON(fiber.Map{"message": "Thanks, your +1 was recorded", "impact_score": score})
+}
+
+// 🧑‍🎓 STUDENT — Open, non-confidential complaints from the student's block, for "+1"
+// Identity is never included; only what the issue is and how many others are affected.
+func GetBlockComplaints(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	var sm models.StudentModel
+	if err := config.DB.Where("user_id = ?", userID).First(&sm).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Student record not found"})
+	}
+
+	var rows []struct {
+		ID          uuid.UUID `json:"id"`
+		Title       string    `json:"title"`
+		Type        string    `json:"type"`
+		Status      string    `json:"status"`
+		ImpactScore int       `json:"impact_score"`
+		RoomNumber  *string   `json:"room_number"`
+		Following   bool      `json:"following"`
+	}
+	err := config.DB.Table("complaints").
+		Select(`complaints.id, complaints.title, complaints.type, complaints.status, complaints.impact_score,
+			rooms.number AS room_number,
+			EXISTS (SELECT 1 FROM complaint_watchers w WHERE w.complaint_id = complaints.id AND w.user_id = ?) AS following`, userID).
+		Joins("LEFT JOIN rooms ON rooms.id = complaints.room_id").
+		Where("complaints.user_id IN (SELECT user_id FROM student_models WHERE block = ?)", sm.Block).
+		Where("complaints.user_id <> ? AND complaints.is_confidential = ?", userID, false).
+		Where("complaints.deleted_at IS NULL").
+		Where("complaints.status IN ?", []models.ComplaintStatus{models.Open, models.InProgress}).
+		Order("complaints.impact_score desc, complaints.created_at desc").
+		Scan(&rows).Error
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch complaints"})
+	}
+	return c.JSON(fiber.Map{"count": len(rows), "data": rows})
+}
+
+// 👀 ADMIN — Followers of a complaint
+func GetComplaintWatchers(c *fiber.Ctx) error {
+	complaint, errResp := loadFollowableComplaint(c)
+	if complaint == nil {
+		return errResp
+	}
+	var rows []struct {
+		UserID uuid.UUID `json:"user_id"`
+		Name   string    `json:"name"`
+		Role   string    `json:"role"`
+		Reason string    `json:"reason"`
+	}
+	if err := config.DB.Table("complaint_watchers").
+		Select("complaint_watchers.user_id, users.name, users.role, complaint_watchers.reason").
+		Joins("JOIN users ON users.id = complaint_watchers.user_id").
+		Where("complaint_watchers.complaint_id = ?", complaint.ID).
+		Order("complaint_watchers.created_at asc").
+		Scan(&rows).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch followers"})
+	}
+	return c.JSON(fiber.Map{"count": len(rows), "impact_score": complaint.ImpactScore, "data": rows})
+}
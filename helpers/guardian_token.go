@@ -0,0 +1,69 @@
+package helpers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidGuardianToken = errors.New("invalid acknowledgement link")
+	ErrExpiredGuardianToken = errors.New("acknowledgement link has expired")
+)
+
+// guardianLinkSecret signs acknowledgement links. Env: GUARDIAN_LINK_SECRET, falling back to JWT_SECRET.
+func guardianLinkSecret() []byte {
+	if s := os.Getenv("GUARDIAN_LINK_SECRET"); s != "" {
+		return []byte(s)
+	}
+	return []byte(os.Getenv("JWT_SECRET"))
+}
+
+func signGuardianPayload(payload string) string {
+	mac := hmac.New(sha256.New, guardianLinkSecret())
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// SignGuardianToken builds the token of a guardian acknowledgement link: <ack id>.<expiry>.<nonce>.<signature>.
+func SignGuardianToken(ackID uuid.UUID, nonce string, expiresAt time.Time) string {
+	payload := fmt.Sprintf("%s.%d.%s", ackID, expiresAt.Unix(), nonce)
+	return payload + "." + signGuardianPayload(payload)
+}
+
+// ParseGuardianToken verifies a token's signature and expiry and returns the acknowledgement id and nonce.
+func ParseGuardianToken(token string) (uuid.UUID, string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 {
+		return uuid.Nil, "", ErrInvalidGuardianToken
+	}
+	payload := strings.Join(parts[:3], ".")
+	if !hmac.Equal([]byte(signGuardianPayload(payload)), []byte(parts[3])) {
+		return uuid.Nil, "", ErrInvalidGuardianToken
+	}
+	id, err := uuid.Parse(parts[0])
+	if err != nil {
+		return uuid.Nil, "", ErrInvalidGuardianToken
+	}
+	exp, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return uuid.Nil, "", ErrInvalidGuardianToken
+	}
+	if time.Now().After(time.Unix(exp, 0)) {
+		return id, parts[2], ErrExpiredGuardianToken
+	}
+	return id, parts[2], nil
+}
+
+// NewLinkNonce returns a random nonce for a guardian link.
+func NewLinkNonce() string {
+	return strings.ReplaceAll(uuid.NewString(), "-", "")
+}
@@ -0,0 +1,62 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestGuardianToken(t *testing.T) {
+	ackID := uuid.New()
+	nonce := NewLinkNonce()
+	t.Setenv("GUARDIAN_LINK_SECRET", "another-secret")
+	foreign := SignGuardianToken(ackID, nonce, time.Now().Add(time.Hour))
+	t.Setenv("GUARDIAN_LINK_SECRET", "test-secret")
+	valid := SignGuardianToken(ackID, nonce, time.Now().Add(time.Hour))
+	parts := strings.Split(valid, ".")
+
+	tests := []struct {
+		name    string
+		token   string
+		wantID  uuid.UUID
+		wantErr error
+	}{
+		{name: "valid", token: valid, wantID: ackID},
+		{name: "expired", token: SignGuardianToken(ackID, nonce, time.Now().Add(-time.Minute)), wantID: ackID, wantErr: ErrExpiredGuardianToken},
+		{name: "tampered nonce", token: strings.Join([]string{parts[0], parts[1], "other", parts[3]}, "."), wantErr: ErrInvalidGuardianToken},
+		{name: "extended expiry", token: strings.Join([]string{parts[0], "99999999999", parts[2], parts[3]}, "."), wantErr: ErrInvalidGuardianToken},
+		{name: "missing signature", token: strings.Join(parts[:3], "."), wantErr: ErrInvalidGuardianToken},
+		{name: "signed with another secret", token: foreign, wantErr: ErrInvalidGuardianToken},
+		{name: "garbage", token: "not-a-token", wantErr: ErrInvalidGuardianToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, gotNonce, err := ParseGuardianToken(tt.token)
+			if err != tt.wantErr {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if id != tt.wantID {
+				t.Errorf("id = %v, want %v", id, tt.wantID)
+			}
+			if tt.wantID != uuid.Nil && gotNonce != nonce {
+				t.Errorf("nonce = %q, want %q", gotNonce, nonce)
+			}
+		})
+	}
+}
+
+func TestGuardianTokenFallsBackToJWTSecret(t *testing.T) {
+	t.Setenv("GUARDIAN_LINK_SECRET", "")
+	t.Setenv("JWT_SECRET", "jwt-secret")
+	ackID := uuid.New()
+	token := SignGuardianToken(ackID, "n", time.Now().Add(time.Hour))
+	if id, _, err := ParseGuardianToken(token); err != nil || id != ackID {
+		t.Fatalf("ParseGuardianToken() = %v, %v; want %v, nil", id, err, ackID)
+	}
+	t.Setenv("JWT_SECRET", "rotated")
+	if _, _, err := ParseGuardianToken(token); err != ErrInvalidGuardianToken {
+		t.Fatalf("error after rotating JWT_SECRET = %v, want %v", err, ErrInvalidGuardianToken)
+	}
+}
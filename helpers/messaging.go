@@ -0,0 +1,150 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OutboundMessage is an email or SMS to someone outside the system, such as a guardian.
+// Subject is ignored by SMS senders.
+type OutboundMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// MessageSender delivers outbound messages over one channel. Implementations are plugged in
+// with SetMailer / SetSMSSender; by default they are chosen from the environment.
+type MessageSender interface {
+	Send(msg OutboundMessage) error
+}
+
+var (
+	sendersMu sync.RWMutex
+	mailer    MessageSender
+	smsSender MessageSender
+)
+
+// SetMailer replaces the email sender.
+func SetMailer(s MessageSender) {
+	sendersMu.Lock()
+	defer sendersMu.Unlock()
+	mailer = s
+}
+
+// SetSMSSender replaces the SMS sender.
+func SetSMSSender(s MessageSender) {
+	sendersMu.Lock()
+	defer sendersMu.Unlock()
+	smsSender = s
+}
+
+// Mailer returns the email sender: SMTP when SMTP_HOST is set, otherwise one that fails every
+// send with ErrChannelNotConfigured.
+// Env: SMTP_HOST, SMTP_PORT (default 587), SMTP_USERNAME, SMTP_PASSWORD, SMTP_FROM.
+func Mailer() MessageSender {
+	sendersMu.RLock()
+	s := mailer
+	sendersMu.RUnlock()
+	if s != nil {
+		return s
+	}
+	if host := os.Getenv("SMTP_HOST"); host != "" {
+		port := os.Getenv("SMTP_PORT")
+		if port == "" {
+			port = "587"
+		}
+		from := os.Getenv("SMTP_FROM")
+		if from == "" {
+			from = os.Getenv("SMTP_USERNAME")
+		}
+		s = smtpMailer{host: host, port: port, username: os.Getenv("SMTP_USERNAME"), password: os.Getenv("SMTP_PASSWORD"), from: from}
+	} else {
+		s = unconfiguredSender{channel: "email"}
+	}
+	SetMailer(s)
+	return s
+}
+
+// SMSSender returns the SMS sender: an HTTP gateway when SMS_WEBHOOK_URL is set, otherwise one
+// that fails every send with ErrChannelNotConfigured. The gateway receives {"to": ..., "body": ...} with SMS_WEBHOOK_TOKEN as bearer token.
+func SMSSender() MessageSender {
+	sendersMu.RLock()
+	s := smsSender
+	sendersMu.RUnlock()
+	if s != nil {
+		return s
+	}
+	if url := os.Getenv("SMS_WEBHOOK_URL"); url != "" {
+		s = webhookSMS{url: url, token: os.Getenv("SMS_WEBHOOK_TOKEN"), client: &http.Client{Timeout: 10 * time.Second}}
+	} else {
+		s = unconfiguredSender{channel: "sms"}
+	}
+	SetSMSSender(s)
+	return s
+}
+
+type smtpMailer struct {
+	host, port, username, password, from string
+}
+
+func (m smtpMailer) Send(msg OutboundMessage) error {
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+	// strip CR/LF so user-provided text cannot inject headers
+	clean := strings.NewReplacer("\r", " ", "\n", " ")
+	body := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s",
+		m.from, clean.Replace(msg.To), clean.Replace(msg.Subject), msg.Body)
+	return smtp.SendMail(m.host+":"+m.port, auth, m.from, []string{msg.To}, []byte(body))
+}
+
+type webhookSMS struct {
+	url, token string
+	client     *http.Client
+}
+
+func (w webhookSMS) Send(msg OutboundMessage) error {
+	payload, _ := json.Marshal(map[string]string{"to": msg.To, "body": msg.Body})
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.token != "" {
+		req.Header.Set("Authorization", "Bearer "+w.token)
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("sms gateway returned %s", resp.Status)
+	}
+	return nil
+}
+
+// ErrChannelNotConfigured is returned when sending over a channel that has no provider set up.
+var ErrChannelNotConfigured = errors.New("channel not configured")
+
+// unconfiguredSender stands in for a channel without a provider. It refuses to send, so callers
+// never treat a message as delivered, and it never logs the body since that may hold a link.
+type unconfiguredSender struct {
+	channel string
+}
+
+func (u unconfiguredSender) Send(msg OutboundMessage) error {
+	log.Printf("✉️ [%s not configured] dropped message to=%s", u.channel, msg.To)
+	return ErrChannelNotConfigured
+}
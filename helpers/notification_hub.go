@@ -0,0 +1,72 @@
+package helpers
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// PushEvent is one server-sent event for a connected user: Name is the SSE event name
+// ("notification", "unread_count") and Data is encoded as JSON.
+type PushEvent struct {
+	Name string
+	Data interface{}
+}
+
+// NotificationHub fans push events out to every open stream of a user within this process.
+// Other server instances are kept in sync by the Postgres listener in jobs, which publishes here.
+type NotificationHub struct {
+	mu   sync.RWMutex
+	subs map[uuid.UUID]map[chan PushEvent]struct{}
+}
+
+var notificationHub = &NotificationHub{subs: map[uuid.UUID]map[chan PushEvent]struct{}{}}
+
+// Notifications returns the process-wide notification hub.
+func Notifications() *NotificationHub {
+	return notificationHub
+}
+
+// Subscribe opens a stream for userID. The returned cancel func must be called when the client goes away.
+func (h *NotificationHub) Subscribe(userID uuid.UUID) (<-chan PushEvent, func()) {
+	ch := make(chan PushEvent, 32)
+	h.mu.Lock()
+	if h.subs[userID] == nil {
+		h.subs[userID] = map[chan PushEvent]struct{}{}
+	}
+	h.subs[userID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subs[userID], ch)
+			if len(h.subs[userID]) == 0 {
+				delete(h.subs, userID)
+			}
+			h.mu.Unlock()
+		})
+	}
+}
+
+// HasSubscribers reports whether userID has an open stream here, so events for users
+// connected to other instances can be skipped without touching the database.
+func (h *NotificationHub) HasSubscribers(userID uuid.UUID) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subs[userID]) > 0
+}
+
+// Publish sends ev to every stream of userID. A stream that is not keeping up misses the
+// event rather than blocking delivery; the next unread_count event resynchronises it.
+func (h *NotificationHub) Publish(userID uuid.UUID, ev PushEvent) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for ch := range h.subs[userID] {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
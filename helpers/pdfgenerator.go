@@ -2,30 +2,370 @@ package helpers
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
 	"github.com/aditisaxena259/mental-health-be/models"
 	"github.com/jung-kurt/gofpdf"
-	"time"
 )
 
-func CreatePDF(apologies []models.Apology) (string, error) {
+// ChartBar is one labelled bar of a report chart.
+type ChartBar struct {
+	Label string
+	Value int
+}
+
+// BlockComplaintSummary is the per-block section of the complaint summary report.
+type BlockComplaintSummary struct {
+	Block              string
+	Total              int
+	Overdue            int
+	AvgResolutionHours *float64
+	ByStatus           []ChartBar
+	ByType             []ChartBar
+}
+
+// report wraps a gofpdf document with the shared letterhead, footer and UTF-8 translation.
+type report struct {
+	pdf *gofpdf.Fpdf
+	tr  func(string) string
+}
+
+var statusColors = map[string][3]int{
+	"open":       {220, 120, 40},
+	"inprogress": {60, 120, 200},
+	"resolved":   {60, 160, 90},
+	"withdrawn":  {150, 150, 150},
+
+	"awaiting_guardian": {170, 110, 190},
+	"submitted":         {220, 120, 40},
+	"reviewed":          {60, 120, 200},
+	"accepted":          {60, 160, 90},
+	"rejected":          {200, 60, 60},
+}
+
+// newDocument starts an A4 document. Every page gets the letterhead (REPORT_ORG_NAME,
+// REPORT_ORG_ADDRESS) and a "Page x of y" footer.
+func newDocument(title string) *report {
 	pdf := gofpdf.New("P", "mm", "A4", "")
+	r := &report{pdf: pdf, tr: pdf.UnicodeTranslatorFromDescriptor("")}
+	org := os.Getenv("REPORT_ORG_NAME")
+	if org == "" {
+		org = "Hostel Administration"
+	}
+	address := os.Getenv("REPORT_ORG_ADDRESS")
+	generated := time.Now().Format("02 Jan 2006 15:04")
+
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 20)
+	pdf.AliasNbPages("")
+	pdf.SetHeaderFunc(func() {
+		pdf.SetFont("Arial", "B", 14)
+		pdf.SetTextColor(30, 50, 90)
+		pdf.CellFormat(110, 7, r.tr(org), "", 0, "L", false, 0, "")
+		pdf.SetFont("Arial", "", 9)
+		pdf.SetTextColor(90, 90, 90)
+		pdf.CellFormat(0, 7, r.tr(title), "", 1, "R", false, 0, "")
+		if address != "" {
+			pdf.CellFormat(0, 5, r.tr(address), "", 1, "L", false, 0, "")
+		}
+		pdf.SetDrawColor(30, 50, 90)
+		pdf.SetLineWidth(0.6)
+		pdf.Line(15, pdf.GetY()+1, 195, pdf.GetY()+1)
+		pdf.Ln(6)
+		pdf.SetTextColor(0, 0, 0)
+	})
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont("Arial", "I", 8)
+		pdf.SetTextColor(120, 120, 120)
+		pdf.CellFormat(90, 10, "Generated "+generated, "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 10, fmt.Sprintf("Page %d of {nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+
 	pdf.AddPage()
-	pdf.SetFont("Arial", "B", 14)
-	pdf.Cell(40, 10, "Monthly Apology Report")
+	return r
+}
 
-	pdf.Ln(12)
+// newReport starts a report document with its title, period and scope on the first page.
+func newReport(title, period, scope string) *report {
+	r := newDocument(title)
+	pdf := r.pdf
+	pdf.SetFont("Arial", "B", 16)
+	pdf.CellFormat(0, 9, r.tr(title), "", 1, "L", false, 0, "")
 	pdf.SetFont("Arial", "", 10)
+	pdf.CellFormat(0, 6, r.tr("Period: "+period), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, r.tr("Scope: "+scope), "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+	return r
+}
+
+func (r *report) heading(text string) {
+	r.pdf.Ln(2)
+	r.pdf.SetFont("Arial", "B", 12)
+	r.pdf.CellFormat(0, 7, r.tr(text), "", 1, "L", false, 0, "")
+	r.pdf.SetFont("Arial", "", 10)
+}
+
+// barChart draws a horizontal bar chart scaled to the largest value.
+func (r *report) barChart(title string, bars []ChartBar) {
+	pdf := r.pdf
+	pdf.SetFont("Arial", "B", 10)
+	pdf.CellFormat(0, 6, r.tr(title), "", 1, "L", false, 0, "")
+	pdf.SetFont("Arial", "", 9)
+	if len(bars) == 0 {
+		pdf.CellFormat(0, 6, "No data", "", 1, "L", false, 0, "")
+		return
+	}
+	max := 0
+	for _, b := range bars {
+		if b.Value > max {
+			max = b.Value
+		}
+	}
+	const labelWidth, chartWidth, barHeight = 40.0, 115.0, 5.0
+	for _, b := range bars {
+		if pdf.GetY()+barHeight+2 > 277 {
+			pdf.AddPage()
+		}
+		y := pdf.GetY()
+		pdf.CellFormat(labelWidth, barHeight+1, r.tr(b.Label), "", 0, "L", false, 0, "")
+		width := 0.0
+		if max > 0 {
+			width = chartWidth * float64(b.Value) / float64(max)
+		}
+		color, ok := statusColors[b.Label]
+		if !ok {
+			color = [3]int{110, 130, 160}
+		}
+		pdf.SetFillColor(color[0], color[1], color[2])
+		if width > 0 {
+			pdf.Rect(15+labelWidth, y+0.5, width, barHeight, "F")
+		}
+		pdf.SetX(15 + labelWidth + width + 2)
+		pdf.CellFormat(20, barHeight+1, fmt.Sprintf("%d", b.Value), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(2)
+}
 
+// CreateApologyReport writes the monthly apology report: status and type charts followed by
+// one entry per apology with the student's name, identifier and the review comment.
+func CreateApologyReport(w io.Writer, period, scope string, apologies []models.Apology) error {
+	r := newReport("Monthly Apology Report", period, scope)
+	pdf := r.pdf
+
+	byStatus := map[string]int{}
+	byType := map[string]int{}
 	for _, a := range apologies {
-		pdf.Cell(190, 10, fmt.Sprintf("StudentID: %s | Type: %s | Status: %s | Date: %s", a.StudentID, a.ApologyType, a.Status, a.CreatedAt.Format("2006-01-02")))
-		pdf.Ln(8)
-		pdf.MultiCell(190, 6, fmt.Sprintf("Comment: %s\nDescription: %s", a.Comment, a.Description), "", "", false)
-		pdf.Ln(4)
+		byStatus[string(a.Status)]++
+		byType[string(a.ApologyType)]++
 	}
+	pdf.CellFormat(0, 6, fmt.Sprintf("Total apologies: %d", len(apologies)), "", 1, "L", false, 0, "")
+	r.barChart("By status", orderedBars(byStatus, "awaiting_guardian", "submitted", "reviewed", "accepted", "rejected"))
+	r.barChart("By type", orderedBars(byType, "outing", "misconduct", "miscellaneous"))
 
-	filename := fmt.Sprintf("apology_report_%d.pdf", time.Now().Unix())
-	path := fmt.Sprintf("./reports/%s", filename)
-	err := pdf.OutputFileAndClose(path)
+	r.heading("Apologies")
+	widths := []float64{22, 48, 30, 18, 28, 34}
+	headers := []string{"Date", "Student", "Student ID", "Room", "Type", "Status"}
+	tableHeader := func() {
+		pdf.SetFont("Arial", "B", 9)
+		pdf.SetFillColor(230, 234, 242)
+		for i, h := range headers {
+			pdf.CellFormat(widths[i], 7, h, "1", 0, "L", true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Arial", "", 9)
+	}
+	tableHeader()
+	for _, a := range apologies {
+		if pdf.GetY() > 260 {
+			pdf.AddPage()
+			tableHeader()
+		}
+		room := a.Student.Block
+		if a.Student.RoomNo != "" {
+			room += "-" + a.Student.RoomNo
+		}
+		cells := []string{a.CreatedAt.Format("02 Jan 2006"), a.Student.User.Name, a.StudentIdentifier, room, string(a.ApologyType), string(a.Status)}
+		for i, v := range cells {
+			pdf.CellFormat(widths[i], 6, r.tr(v), "1", 0, "L", false, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Arial", "", 8)
+		pdf.MultiCell(0, 4.5, r.tr("Letter: "+a.Message), "LR", "L", false)
+		comment := a.Comment
+		if comment == "" {
+			comment = "-"
+		}
+		pdf.SetFont("Arial", "I", 8)
+		pdf.MultiCell(0, 4.5, r.tr("Review comment: "+comment), "LRB", "L", false)
+		pdf.SetFont("Arial", "", 9)
+	}
+	if len(apologies) == 0 {
+		pdf.CellFormat(0, 7, "No apologies were submitted in this period.", "", 1, "L", false, 0, "")
+	}
+	return pdf.Output(w)
+}
+
+// CreateComplaintSummaryReport writes the complaint summary: overall status chart followed
+// by a section per block with status and type charts, overdue count and resolution time.
+func CreateComplaintSummaryReport(w io.Writer, period, scope string, blocks []BlockComplaintSummary) error {
+	r := newReport("Complaint Summary by Block", period, scope)
+	pdf := r.pdf
+
+	overall := map[string]int{}
+	total := 0
+	for _, b := range blocks {
+		total += b.Total
+		for _, s := range b.ByStatus {
+			overall[s.Label] += s.Value
+		}
+	}
+	pdf.CellFormat(0, 6, fmt.Sprintf("Total complaints: %d across %d block(s)", total, len(blocks)), "", 1, "L", false, 0, "")
+	r.barChart("All blocks by status", orderedBars(overall, "open", "inprogress", "resolved", "withdrawn"))
+
+	for _, b := range blocks {
+		if pdf.GetY() > 200 {
+			pdf.AddPage()
+		}
+		r.heading(fmt.Sprintf("Block %s - %d complaint(s)", b.Block, b.Total))
+		avg := "n/a"
+		if b.AvgResolutionHours != nil {
+			avg = fmt.Sprintf("%.1f h", *b.AvgResolutionHours)
+		}
+		pdf.CellFormat(0, 6, fmt.Sprintf("Overdue and still open: %d    Average resolution time: %s", b.Overdue, avg), "", 1, "L", false, 0, "")
+		r.barChart("By status", b.ByStatus)
+		r.barChart("By type", b.ByType)
+	}
+	if len(blocks) == 0 {
+		pdf.CellFormat(0, 7, "No complaints were filed in this period.", "", 1, "L", false, 0, "")
+	}
+	return pdf.Output(w)
+}
+
+// CreateApologyLetter writes a single apology as a formal letter: addressee, subject, the letter
+// rendered from its template (or the free-text message), the structured fields and the signature.
+// fields supplies labels and order for the structured values; unknown keys follow alphabetically.
+func CreateApologyLetter(w io.Writer, apology models.Apology, subject string, fields []models.ApologyTemplateField) error {
+	r := newDocument("Apology Letter")
+	pdf := r.pdf
+	student := apology.Student
+
+	pdf.SetFont("Arial", "", 10)
+	pdf.CellFormat(0, 6, apology.CreatedAt.Format("02 January 2006"), "", 1, "R", false, 0, "")
+	pdf.Ln(2)
+	to := "The Warden"
+	if student.Block != "" {
+		to += ", Block " + student.Block
+	}
+	pdf.CellFormat(0, 6, r.tr("To: "+to), "", 1, "L", false, 0, "")
+	pdf.Ln(2)
+	if subject == "" {
+		subject = "Apology (" + string(apology.ApologyType) + ")"
+	}
+	pdf.SetFont("Arial", "B", 11)
+	pdf.CellFormat(0, 7, r.tr("Subject: "+subject), "", 1, "L", false, 0, "")
+	pdf.Ln(3)
+
+	body := apology.Letter
+	if body == "" {
+		body = apology.Message
+	}
+	pdf.SetFont("Arial", "", 11)
+	pdf.MultiCell(0, 6, r.tr(body), "", "L", false)
+
+	if len(apology.Fields) > 0 {
+		r.heading("Details")
+		labels := map[string]string{}
+		keys := []string{}
+		for _, f := range fields {
+			if _, ok := apology.Fields[f.Key]; ok {
+				labels[f.Key] = f.Label
+				keys = append(keys, f.Key)
+			}
+		}
+		extra := []string{}
+		for key := range apology.Fields {
+			if _, ok := labels[key]; !ok {
+				extra = append(extra, key)
+			}
+		}
+		sort.Strings(extra)
+		pdf.SetFont("Arial", "", 10)
+		for _, key := range append(keys, extra...) {
+			label := labels[key]
+			if label == "" {
+				label = key
+			}
+			pdf.SetFont("Arial", "B", 10)
+			pdf.CellFormat(60, 6, r.tr(label), "1", 0, "L", false, 0, "")
+			pdf.SetFont("Arial", "", 10)
+			pdf.MultiCell(0, 6, r.tr(fieldText(apology.Fields[key])), "1", "L", false)
+		}
+	}
+
+	pdf.Ln(10)
+	pdf.SetFont("Arial", "", 11)
+	pdf.CellFormat(0, 6, "Yours sincerely,", "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+	pdf.SetFont("Arial", "B", 11)
+	pdf.CellFormat(0, 6, r.tr(student.User.Name), "", 1, "L", false, 0, "")
+	pdf.SetFont("Arial", "", 10)
+	identity := apology.StudentIdentifier
+	if student.RoomNo != "" {
+		identity += fmt.Sprintf(", Room %s-%s", student.Block, student.RoomNo)
+	}
+	pdf.CellFormat(0, 6, r.tr(identity), "", 1, "L", false, 0, "")
+
+	pdf.Ln(6)
+	pdf.SetFont("Arial", "I", 9)
+	pdf.SetTextColor(90, 90, 90)
+	pdf.CellFormat(0, 5, r.tr(fmt.Sprintf("Reference %s - status: %s", apology.ID, apology.Status)), "", 1, "L", false, 0, "")
+	return pdf.Output(w)
+}
+
+// fieldText formats a stored structured value for display.
+func fieldText(v interface{}) string {
+	switch val := v.(type) {
+	case bool:
+		if val {
+			return "Yes"
+		}
+		return "No"
+	case []interface{}:
+		items := make([]string, 0, len(val))
+		for _, item := range val {
+			items = append(items, fmt.Sprint(item))
+		}
+		return strings.Join(items, ", ")
+	case []string:
+		return strings.Join(val, ", ")
+	case nil:
+		return "-"
+	}
+	return fmt.Sprint(v)
+}
 
-	return path, err
+// orderedBars lists the known labels first, in order, followed by any other label alphabetically.
+func orderedBars(counts map[string]int, known ...string) []ChartBar {
+	bars := []ChartBar{}
+	seen := map[string]bool{}
+	for _, label := range known {
+		bars = append(bars, ChartBar{Label: label, Value: counts[label]})
+		seen[label] = true
+	}
+	extra := []string{}
+	for label := range counts {
+		if !seen[label] {
+			extra = append(extra, label)
+		}
+	}
+	sort.Strings(extra)
+	for _, label := range extra {
+		bars = append(bars, ChartBar{Label: label, Value: counts[label]})
+	}
+	return bars
 }
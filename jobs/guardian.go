@@ -0,0 +1,275 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aditisaxena259/mental-health-be/config"
+	"github.com/aditisaxena259/mental-health-be/helpers"
+	"github.com/aditisaxena259/mental-health-be/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GuardianConfig controls guardian acknowledgements of apologies.
+// Env: GUARDIAN_ACK_TYPES (comma separated apology types, default "misconduct"),
+// GUARDIAN_ACK_EXPIRY_HOURS (default 72), GUARDIAN_REMINDER_HOURS (default 24),
+// GUARDIAN_MAX_REMINDERS (default 2), GUARDIAN_ACK_URL (link base, default
+// http://localhost:8080/api/guardian/acknowledgements), GUARDIAN_CHECK_INTERVAL (Go duration, default 15m).
+type GuardianConfig struct {
+	Types         []models.ApologyType
+	ExpiryHours   int
+	ReminderHours int
+	MaxReminders  int
+	LinkBaseURL   string
+	Interval      time.Duration
+}
+
+func GuardianConfigFromEnv() GuardianConfig {
+	cfg := GuardianConfig{
+		Types:         []models.ApologyType{models.ApologyForMisconduct},
+		ExpiryHours:   72,
+		ReminderHours: 24,
+		MaxReminders:  2,
+		LinkBaseURL:   "http://localhost:8080/api/guardian/acknowledgements",
+		Interval:      15 * time.Minute,
+	}
+	if v := os.Getenv("GUARDIAN_ACK_TYPES"); v != "" {
+		cfg.Types = nil
+		for _, t := range strings.Split(v, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				cfg.Types = append(cfg.Types, models.ApologyType(t))
+			}
+		}
+	}
+	if v, err := strconv.Atoi(os.Getenv("GUARDIAN_ACK_EXPIRY_HOURS")); err == nil && v > 0 {
+		cfg.ExpiryHours = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("GUARDIAN_REMINDER_HOURS")); err == nil && v > 0 {
+		cfg.ReminderHours = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("GUARDIAN_MAX_REMINDERS")); err == nil && v >= 0 {
+		cfg.MaxReminders = v
+	}
+	if v := os.Getenv("GUARDIAN_ACK_URL"); v != "" {
+		cfg.LinkBaseURL = strings.TrimRight(v, "/")
+	}
+	if v, err := time.ParseDuration(os.Getenv("GUARDIAN_CHECK_INTERVAL")); err == nil && v > 0 {
+		cfg.Interval = v
+	}
+	return cfg
+}
+
+// Requires reports whether apologies of type t need a guardian acknowledgement.
+func (cfg GuardianConfig) Requires(t models.ApologyType) bool {
+	for _, required := range cfg.Types {
+		if required == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Link is the acknowledgement URL for the current nonce of ack.
+func (cfg GuardianConfig) Link(ack models.GuardianAcknowledgement) string {
+	return cfg.LinkBaseURL + "/" + helpers.SignGuardianToken(ack.ID, ack.LinkNonce, ack.ExpiresAt)
+}
+
+var guardianAckWake = make(chan struct{}, 1)
+
+// WakeGuardianAckMonitor asks the monitor to run now rather than at its next tick. Call it after
+// committing an acknowledgement whose link has not been sent yet.
+func WakeGuardianAckMonitor() {
+	select {
+	case guardianAckWake <- struct{}{}:
+	default:
+	}
+}
+
+// StartGuardianAckMonitor runs ProcessGuardianAcks once at startup, then on every tick or WakeGuardianAckMonitor call.
+func StartGuardianAckMonitor(cfg GuardianConfig) {
+	go func() {
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+		for {
+			if sent, reminded, expired, err := ProcessGuardianAcks(cfg); err != nil {
+				log.Println("⚠️ Guardian acknowledgement check failed:", err)
+			} else if sent+reminded+expired > 0 {
+				log.Printf("👪 Guardian acknowledgements: %d link(s) sent, %d reminder(s) sent, %d expired", sent, reminded, expired)
+			}
+			select {
+			case <-ticker.C:
+			case <-guardianAckWake:
+			}
+		}
+	}()
+}
+
+// ProcessGuardianAcks expires pending acknowledgements past their deadline, sends the links that
+// have not gone out yet (retrying failed sends on every run) and sends due reminders.
+// An expired acknowledgement releases its apology to the warden, who sees the expired status.
+func ProcessGuardianAcks(cfg GuardianConfig) (int, int, int, error) {
+	now := time.Now()
+
+	var expiredAcks []models.GuardianAcknowledgement
+	if err := config.DB.Where("status = ? AND expires_at <= ?", models.GuardianAckPending, now).Find(&expiredAcks).Error; err != nil {
+		return 0, 0, 0, err
+	}
+	expired := 0
+	for _, ack := range expiredAcks {
+		released := false
+		err := config.DB.Transaction(func(tx *gorm.DB) error {
+			res := tx.Model(&models.GuardianAcknowledgement{}).
+				Where("id = ? AND status = ?", ack.ID, models.GuardianAckPending).
+				Update("status", models.GuardianAckExpired)
+			if res.Error != nil || res.RowsAffected == 0 {
+				return res.Error
+			}
+			released = true
+			res = tx.Model(&models.Apology{}).
+				Where("id = ? AND status = ?", ack.ApologyID, models.ApologyAwaitingGuardian).
+				Updates(map[string]interface{}{"status": models.ApologySubmitted, "version": gorm.Expr("version + 1")})
+			if res.Error != nil {
+				return res.Error
+			}
+			event := models.ApologyEvent{
+				ID: uuid.New(), ApologyID: ack.ApologyID, EventType: models.ApologyEventGuardian,
+				ActorRole: "system", Comment: "Guardian acknowledgement expired",
+			}
+			if res.RowsAffected > 0 {
+				event.FromStatus, event.ToStatus = models.ApologyAwaitingGuardian, models.ApologySubmitted
+			}
+			if err := tx.Create(&event).Error; err != nil {
+				return err
+			}
+			return notifyGuardianAckExpired(tx, ack)
+		})
+		if err != nil {
+			return 0, 0, expired, err
+		}
+		if released {
+			expired++
+		}
+	}
+	if expired > 0 {
+		WakeOutbox()
+	}
+
+	var unsentAcks []models.GuardianAcknowledgement
+	if err := config.DB.Where("status = ? AND sent_at IS NULL", models.GuardianAckPending).Find(&unsentAcks).Error; err != nil {
+		return 0, 0, expired, err
+	}
+	sent := 0
+	for _, ack := range unsentAcks {
+		if err := SendGuardianRequest(cfg, ack, false); err != nil {
+			log.Printf("⚠️ Guardian acknowledgement link for apology %s not sent: %v", ack.ApologyID, err)
+			continue
+		}
+		sent++
+	}
+
+	var dueAcks []models.GuardianAcknowledgement
+	remindBefore := now.Add(-time.Duration(cfg.ReminderHours) * time.Hour)
+	if err := config.DB.Where("status = ? AND sent_at IS NOT NULL AND reminders_sent < ? AND COALESCE(last_reminder_at, sent_at) <= ?",
+		models.GuardianAckPending, cfg.MaxReminders, remindBefore).Find(&dueAcks).Error; err != nil {
+		return sent, 0, expired, err
+	}
+	reminded := 0
+	for _, ack := range dueAcks {
+		if err := SendGuardianRequest(cfg, ack, true); err != nil {
+			log.Printf("⚠️ Guardian reminder for apology %s failed: %v", ack.ApologyID, err)
+			continue
+		}
+		reminded++
+	}
+	return sent, reminded, expired, nil
+}
+
+// SendGuardianRequest emails and/or texts the guardian the acknowledgement link and records the
+// send (or reminder). It fails only when no channel could deliver the message.
+func SendGuardianRequest(cfg GuardianConfig, ack models.GuardianAcknowledgement, reminder bool) error {
+	var apology models.Apology
+	if err := config.DB.Preload("Student.User").First(&apology, "id = ?", ack.ApologyID).Error; err != nil {
+		return err
+	}
+	subject := fmt.Sprintf("Please acknowledge %s's apology", apology.Student.User.Name)
+	if reminder {
+		subject = "Reminder: " + subject
+	}
+	greeting := "Dear Parent/Guardian"
+	if ack.GuardianName != "" {
+		greeting = "Dear " + ack.GuardianName
+	}
+	body := fmt.Sprintf("%s,\n\n%s (%s, Block %s) has submitted a %s apology to the hostel administration. "+
+		"Hostel rules require a parent or guardian to acknowledge it.\n\nReview and acknowledge it here before %s:\n%s\n\n"+
+		"This link can be used once.",
+		greeting, apology.Student.User.Name, apology.StudentIdentifier, apology.Student.Block, apology.ApologyType,
+		ack.ExpiresAt.Format("02 Jan 2006 15:04"), cfg.Link(ack))
+
+	var errs []string
+	delivered := false
+	if ack.GuardianEmail != "" {
+		if err := helpers.Mailer().Send(helpers.OutboundMessage{To: ack.GuardianEmail, Subject: subject, Body: body}); err != nil {
+			errs = append(errs, "email: "+err.Error())
+		} else {
+			delivered = true
+		}
+	}
+	if ack.GuardianPhone != "" {
+		sms := fmt.Sprintf("Please acknowledge %s's hostel apology before %s: %s",
+			apology.Student.User.Name, ack.ExpiresAt.Format("02 Jan 15:04"), cfg.Link(ack))
+		if reminder {
+			sms = "Reminder: " + sms
+		}
+		if err := helpers.SMSSender().Send(helpers.OutboundMessage{To: ack.GuardianPhone, Body: sms}); err != nil {
+			errs = append(errs, "sms: "+err.Error())
+		} else {
+			delivered = true
+		}
+	}
+	if !delivered {
+		if len(errs) == 0 {
+			return errors.New("no guardian contact")
+		}
+		return errors.New(strings.Join(errs, "; "))
+	}
+
+	now := time.Now()
+	changes := map[string]interface{}{"sent_at": now}
+	if reminder {
+		changes = map[string]interface{}{"reminders_sent": gorm.Expr("reminders_sent + 1"), "last_reminder_at": now}
+	}
+	return config.DB.Model(&models.GuardianAcknowledgement{}).Where("id = ?", ack.ID).Updates(changes).Error
+}
+
+// notifyGuardianAckExpired queues notices in tx telling the student and the block wardens that the
+// apology went to review unacknowledged.
+func notifyGuardianAckExpired(tx *gorm.DB, ack models.GuardianAcknowledgement) error {
+	var sm models.StudentModel
+	if err := tx.Preload("User").Where("user_id = ?", ack.StudentID).First(&sm).Error; err != nil {
+		return err
+	}
+	if err := EnqueueNotification(tx, models.NotificationFanout{
+		UserIDs:     []uuid.UUID{ack.StudentID},
+		Title:       "Guardian Acknowledgement Expired",
+		Message:     "Your guardian did not acknowledge your apology in time. It has been sent to the warden for review.",
+		Type:        "warning",
+		RelatedID:   &ack.ApologyID,
+		RelatedType: "apology",
+	}); err != nil {
+		return err
+	}
+	return EnqueueNotification(tx, models.NotificationFanout{
+		Block:       sm.Block,
+		Title:       "Apology Without Guardian Acknowledgement",
+		Message:     fmt.Sprintf("The guardian of %s (%s) did not acknowledge their apology; it is now awaiting review.", sm.User.Name, sm.StudentIdentifier),
+		Type:        "warning",
+		RelatedID:   &ack.ApologyID,
+		RelatedType: "apology",
+	})
+}
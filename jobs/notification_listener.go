@@ -0,0 +1,136 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"time"
+
+	"github.com/aditisaxena259/mental-health-be/config"
+	"github.com/aditisaxena259/mental-health-be/helpers"
+	"github.com/aditisaxena259/mental-health-be/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// NotificationListenerConfig controls the LISTEN connection that feeds the notification hub.
+// Env: REALTIME_DATABASE_URL (defaults to DATABASE_URL; must be a direct or session-pooled
+// connection, as LISTEN does not work through a transaction pooler), REALTIME_RECONNECT_DELAY
+// (Go duration, default 5s).
+type NotificationListenerConfig struct {
+	DatabaseURL    string
+	ReconnectDelay time.Duration
+}
+
+func NotificationListenerConfigFromEnv() NotificationListenerConfig {
+	cfg := NotificationListenerConfig{DatabaseURL: os.Getenv("DATABASE_URL"), ReconnectDelay: 5 * time.Second}
+	if v := os.Getenv("REALTIME_DATABASE_URL"); v != "" {
+		cfg.DatabaseURL = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("REALTIME_RECONNECT_DELAY")); err == nil && v > 0 {
+		cfg.ReconnectDelay = v
+	}
+	return cfg
+}
+
+// notificationChange is the payload the notifications trigger sends on models.NotificationChannel.
+type notificationChange struct {
+	Op     string    `json:"op"` // INSERT, UPDATE or DELETE
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+// StartNotificationListener listens for notification changes from every server instance and
+// publishes them to the users connected to this one, reconnecting whenever the connection drops.
+func StartNotificationListener(cfg NotificationListenerConfig) {
+	go func() {
+		for {
+			if err := listenForNotifications(cfg); err != nil {
+				log.Println("⚠️ Notification listener disconnected:", err)
+			}
+			time.Sleep(cfg.ReconnectDelay)
+		}
+	}()
+}
+
+func listenForNotifications(cfg NotificationListenerConfig) error {
+	ctx := context.Background()
+	connCfg, err := pgx.ParseConfig(cfg.DatabaseURL)
+	if err != nil {
+		return err
+	}
+	connCfg.DefaultQueryExecMode = pgx.QueryExecModeSimpleProtocol
+	conn, err := pgx.ConnectConfig(ctx, connCfg)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(ctx)
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{models.NotificationChannel}.Sanitize()); err != nil {
+		return err
+	}
+	log.Println("🔔 Listening for notification changes")
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		// a bulk change (e.g. mark all read) arrives as a burst; collect it so each user
+		// gets one unread count instead of one per row
+		batch := []string{n.Payload}
+		for len(batch) < 500 {
+			waitCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+			n, err = conn.WaitForNotification(waitCtx)
+			cancel()
+			if err != nil {
+				if waitCtx.Err() != nil {
+					break // the burst is over
+				}
+				return err
+			}
+			batch = append(batch, n.Payload)
+		}
+		publishNotificationChanges(batch)
+	}
+}
+
+// publishNotificationChanges pushes new notifications and fresh unread counts to the hub,
+// skipping users without an open stream on this instance.
+func publishNotificationChanges(payloads []string) {
+	hub := helpers.Notifications()
+	touched := map[uuid.UUID]bool{}
+	order := []uuid.UUID{}
+	for _, p := range payloads {
+		var change notificationChange
+		if err := json.Unmarshal([]byte(p), &change); err != nil {
+			log.Println("⚠️ Ignoring malformed notification change:", err)
+			continue
+		}
+		if !hub.HasSubscribers(change.UserID) {
+			continue
+		}
+		if change.Op == "INSERT" {
+			var note models.Notification
+			if err := config.DB.First(&note, "id = ?", change.ID).Error; err == nil {
+				hub.Publish(change.UserID, helpers.PushEvent{Name: "notification", Data: note})
+			}
+		}
+		if !touched[change.UserID] {
+			touched[change.UserID] = true
+			order = append(order, change.UserID)
+		}
+	}
+	for _, userID := range order {
+		publishUnreadCount(userID)
+	}
+}
+
+// publishUnreadCount sends the user's current unread count to their open streams.
+func publishUnreadCount(userID uuid.UUID) {
+	var unread int64
+	if err := config.DB.Model(&models.Notification{}).Where("user_id = ? AND is_read = false", userID).Count(&unread).Error; err != nil {
+		return
+	}
+	helpers.Notifications().Publish(userID, helpers.PushEvent{Name: "unread_count", Data: map[string]int64{"unreadCount": unread}})
+}
@@ -0,0 +1,253 @@
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/aditisaxena259/mental-health-be/config"
+	"github.com/aditisaxena259/mental-health-be/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OutboxConfig controls the outbox worker.
+// Env: OUTBOX_POLL_INTERVAL (Go duration, default 5s), OUTBOX_BATCH_SIZE (default 50),
+// OUTBOX_MAX_ATTEMPTS (default 8, then the event is dead-lettered), OUTBOX_RETRY_BASE (Go duration,
+// default 10s, doubled per attempt up to 1h), OUTBOX_RETENTION_DAYS (processed events kept, default 7).
+type OutboxConfig struct {
+	Interval      time.Duration
+	BatchSize     int
+	MaxAttempts   int
+	RetryBase     time.Duration
+	RetentionDays int
+}
+
+func OutboxConfigFromEnv() OutboxConfig {
+	cfg := OutboxConfig{Interval: 5 * time.Second, BatchSize: 50, MaxAttempts: 8, RetryBase: 10 * time.Second, RetentionDays: 7}
+	if v, err := time.ParseDuration(os.Getenv("OUTBOX_POLL_INTERVAL")); err == nil && v > 0 {
+		cfg.Interval = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("OUTBOX_BATCH_SIZE")); err == nil && v > 0 {
+		cfg.BatchSize = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("OUTBOX_MAX_ATTEMPTS")); err == nil && v > 0 {
+		cfg.MaxAttempts = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("OUTBOX_RETRY_BASE")); err == nil && v > 0 {
+		cfg.RetryBase = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("OUTBOX_RETENTION_DAYS")); err == nil && v > 0 {
+		cfg.RetentionDays = v
+	}
+	return cfg
+}
+
+// retryDelay is the wait before the next attempt once attempts have failed.
+func (cfg OutboxConfig) retryDelay(attempts int) time.Duration {
+	delay := cfg.RetryBase
+	for i := 1; i < attempts && delay < time.Hour; i++ {
+		delay *= 2
+	}
+	if delay > time.Hour {
+		delay = time.Hour
+	}
+	return delay
+}
+
+// attemptUpdates records an attempt at an event that had already been tried attempts times: done
+// when handleErr is nil, otherwise scheduled for a retry, or dead-lettered once MaxAttempts is
+// reached or the event cannot be handled at all.
+func (cfg OutboxConfig) attemptUpdates(attempts int, handleErr error, now time.Time) map[string]interface{} {
+	updates := map[string]interface{}{"attempts": attempts + 1}
+	if handleErr == nil {
+		updates["status"] = models.OutboxDone
+		updates["processed_at"] = now
+		updates["last_error"] = ""
+		return updates
+	}
+	updates["last_error"] = handleErr.Error()
+	if attempts+1 >= cfg.MaxAttempts || errors.Is(handleErr, errUnknownOutboxKind) {
+		updates["status"] = models.OutboxDead
+	} else {
+		updates["next_attempt_at"] = now.Add(cfg.retryDelay(attempts + 1))
+	}
+	return updates
+}
+
+// errUnknownOutboxKind dead-letters an event straight away; retrying cannot help.
+var errUnknownOutboxKind = errors.New("unknown outbox event kind")
+
+// outboxHandlers carry out an event inside the worker's transaction. They must only write
+// through tx so a failed attempt leaves nothing behind.
+var outboxHandlers = map[string]func(tx *gorm.DB, ev models.OutboxEvent) error{
+	models.OutboxKindNotify: deliverNotificationFanout,
+}
+
+var outboxWake = make(chan struct{}, 1)
+
+// WakeOutbox asks the worker to process the outbox now rather than at its next tick. Call it
+// after committing a transaction that enqueued events.
+func WakeOutbox() {
+	select {
+	case outboxWake <- struct{}{}:
+	default:
+	}
+}
+
+// EnqueueNotification records a notification fan-out in tx, the transaction of the change it reports.
+func EnqueueNotification(tx *gorm.DB, n models.NotificationFanout) error {
+	raw, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	payload := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return err
+	}
+	return tx.Create(&models.OutboxEvent{
+		ID:            uuid.New(),
+		Kind:          models.OutboxKindNotify,
+		Payload:       payload,
+		Status:        models.OutboxPending,
+		NextAttemptAt: time.Now(),
+	}).Error
+}
+
+// StartOutboxWorker runs ProcessOutbox once at startup, then on every tick or WakeOutbox call.
+func StartOutboxWorker(cfg OutboxConfig) {
+	go func() {
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+		lastCleanup := time.Time{}
+		for {
+			if processed, failed, err := ProcessOutbox(cfg); err != nil {
+				log.Println("⚠️ Outbox processing failed:", err)
+			} else if failed > 0 {
+				log.Printf("📤 Outbox: %d event(s) processed, %d failed", processed, failed)
+			}
+			if time.Since(lastCleanup) > time.Hour {
+				lastCleanup = time.Now()
+				config.DB.Where("status = ? AND processed_at < ?", models.OutboxDone,
+					time.Now().AddDate(0, 0, -cfg.RetentionDays)).Delete(&models.OutboxEvent{})
+			}
+			select {
+			case <-ticker.C:
+			case <-outboxWake:
+			}
+		}
+	}()
+}
+
+// ProcessOutbox handles up to BatchSize due events. Each event is claimed with FOR UPDATE SKIP LOCKED,
+// so several server instances can run the worker, and is marked done in the same transaction as its
+// handler's writes. A failing handler is rolled back to a savepoint and the attempt is recorded with
+// exponential backoff; after MaxAttempts the event is dead-lettered.
+func ProcessOutbox(cfg OutboxConfig) (int, int, error) {
+	processed, failed := 0, 0
+	for processed+failed < cfg.BatchSize {
+		claimed := false
+		err := config.DB.Transaction(func(tx *gorm.DB) error {
+			var ev models.OutboxEvent
+			res := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("status = ? AND next_attempt_at <= ?", models.OutboxPending, time.Now()).
+				Order("next_attempt_at").Limit(1).Find(&ev)
+			if res.Error != nil || res.RowsAffected == 0 {
+				return res.Error
+			}
+			claimed = true
+
+			handleErr := tx.Transaction(func(inner *gorm.DB) error {
+				handler, ok := outboxHandlers[ev.Kind]
+				if !ok {
+					return fmt.Errorf("%w %q", errUnknownOutboxKind, ev.Kind)
+				}
+				return handler(inner, ev)
+			})
+			updates := cfg.attemptUpdates(ev.Attempts, handleErr, time.Now())
+			if handleErr == nil {
+				processed++
+			} else {
+				failed++
+			}
+			if updates["status"] == models.OutboxDead {
+				log.Printf("☠️ Outbox event %s (%s) dead-lettered after %d attempt(s): %v", ev.ID, ev.Kind, ev.Attempts+1, handleErr)
+			}
+			return tx.Model(&models.OutboxEvent{}).Where("id = ?", ev.ID).Updates(updates).Error
+		})
+		if err != nil {
+			return processed, failed, err
+		}
+		if !claimed {
+			break
+		}
+	}
+	return processed, failed, nil
+}
+
+// deliverNotificationFanout creates one notification per recipient. Notification IDs are derived
+// from the event and the recipient, so delivering the same event twice cannot duplicate them.
+func deliverNotificationFanout(tx *gorm.DB, ev models.OutboxEvent) error {
+	raw, err := json.Marshal(ev.Payload)
+	if err != nil {
+		return err
+	}
+	var n models.NotificationFanout
+	if err := json.Unmarshal(raw, &n); err != nil {
+		return err
+	}
+
+	recipients := append([]uuid.UUID{}, n.UserIDs...)
+	if n.Block != "" {
+		var wardens []models.User
+		if err := tx.Where("role = ? AND block = ?", models.Admin, n.Block).Find(&wardens).Error; err != nil {
+			return err
+		}
+		for _, w := range wardens {
+			recipients = append(recipients, w.ID)
+		}
+	}
+	if n.Chiefs {
+		var chiefs []models.User
+		if err := tx.Where("role = ?", models.ChiefAdmin).Find(&chiefs).Error; err != nil {
+			return err
+		}
+		for _, chief := range chiefs {
+			recipients = append(recipients, chief.ID)
+		}
+	}
+
+	if n.Type == "" {
+		n.Type = "info"
+	}
+	seen := map[uuid.UUID]bool{}
+	notes := []models.Notification{}
+	for _, userID := range recipients {
+		if seen[userID] {
+			continue
+		}
+		seen[userID] = true
+		note := models.Notification{
+			ID:        uuid.NewSHA1(ev.ID, userID[:]),
+			UserID:    userID,
+			Title:     n.Title,
+			Message:   n.Message,
+			Type:      n.Type,
+			RelatedID: n.RelatedID,
+		}
+		if n.RelatedType != "" {
+			rtype := n.RelatedType
+			note.RelatedType = &rtype
+		}
+		notes = append(notes, note)
+	}
+	if len(notes) == 0 {
+		return nil
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&notes, 100).Error
+}
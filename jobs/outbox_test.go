@@ -0,0 +1,79 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aditisaxena259/mental-health-be/models"
+)
+
+func TestOutboxRetryDelay(t *testing.T) {
+	cfg := OutboxConfig{RetryBase: 10 * time.Second}
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{3, 40 * time.Second},
+		{8, 1280 * time.Second},
+		{9, 2560 * time.Second},
+		{10, time.Hour},
+		{50, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := cfg.retryDelay(tt.attempts); got != tt.want {
+			t.Errorf("retryDelay(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestOutboxAttemptUpdates(t *testing.T) {
+	cfg := OutboxConfig{MaxAttempts: 3, RetryBase: 10 * time.Second}
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	failure := errors.New("db unavailable")
+	tests := []struct {
+		name      string
+		attempts  int
+		err       error
+		status    interface{} // nil when the event stays pending
+		nextRetry time.Duration
+	}{
+		{name: "first attempt succeeds", attempts: 0, status: models.OutboxDone},
+		{name: "retry succeeds", attempts: 2, status: models.OutboxDone},
+		{name: "first failure is retried", attempts: 0, err: failure, nextRetry: 10 * time.Second},
+		{name: "second failure backs off", attempts: 1, err: failure, nextRetry: 20 * time.Second},
+		{name: "last attempt fails", attempts: 2, err: failure, status: models.OutboxDead},
+		{name: "unknown kind is dead at once", attempts: 0, err: fmt.Errorf("%w %q", errUnknownOutboxKind, "x"), status: models.OutboxDead},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cfg.attemptUpdates(tt.attempts, tt.err, now)
+			if got["attempts"] != tt.attempts+1 {
+				t.Errorf("attempts = %v, want %d", got["attempts"], tt.attempts+1)
+			}
+			if got["status"] != tt.status {
+				t.Errorf("status = %v, want %v", got["status"], tt.status)
+			}
+			next, scheduled := got["next_attempt_at"]
+			if tt.nextRetry == 0 && scheduled {
+				t.Errorf("next_attempt_at = %v, want none", next)
+			}
+			if tt.nextRetry != 0 && next != now.Add(tt.nextRetry) {
+				t.Errorf("next_attempt_at = %v, want %v", next, now.Add(tt.nextRetry))
+			}
+			wantError := ""
+			if tt.err != nil {
+				wantError = tt.err.Error()
+			}
+			if got["last_error"] != wantError {
+				t.Errorf("last_error = %q, want %q", got["last_error"], wantError)
+			}
+			if _, done := got["processed_at"]; done != (tt.err == nil) {
+				t.Errorf("processed_at set = %v, want %v", done, tt.err == nil)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package jobs
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/aditisaxena259/mental-health-be/config"
+	"github.com/aditisaxena259/mental-health-be/helpers"
+	"github.com/aditisaxena259/mental-health-be/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TrashConfig controls how long soft-deleted complaints and apologies stay restorable.
+// Env: TRASH_RETENTION_DAYS (default 30), TRASH_PURGE_INTERVAL (Go duration, default 1h).
+type TrashConfig struct {
+	RetentionDays int
+	Interval      time.Duration
+}
+
+func TrashConfigFromEnv() TrashConfig {
+	cfg := TrashConfig{RetentionDays: 30, Interval: time.Hour}
+	if v, err := strconv.Atoi(os.Getenv("TRASH_RETENTION_DAYS")); err == nil && v > 0 {
+		cfg.RetentionDays = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("TRASH_PURGE_INTERVAL")); err == nil && v > 0 {
+		cfg.Interval = v
+	}
+	return cfg
+}
+
+// RestorableUntil is the moment an item deleted at deletedAt gets purged.
+func (cfg TrashConfig) RestorableUntil(deletedAt time.Time) time.Time {
+	return deletedAt.AddDate(0, 0, cfg.RetentionDays)
+}
+
+// StartTrashPurger runs PurgeExpiredTrash once at startup and then on every tick.
+func StartTrashPurger(cfg TrashConfig) {
+	go func() {
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+		for {
+			if complaints, apologies, err := PurgeExpiredTrash(cfg); err != nil {
+				log.Println("⚠️ Trash purge failed:", err)
+			} else if complaints+apologies > 0 {
+				log.Printf("🗑️ Trash purge removed %d complaint(s) and %d apolog(ies)", complaints, apologies)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// PurgeExpiredTrash permanently deletes complaints and apologies that have been in the
+// trash for longer than the retention window.
+func PurgeExpiredTrash(cfg TrashConfig) (int, int, error) {
+	cutoff := time.Now().AddDate(0, 0, -cfg.RetentionDays)
+
+	var complaintIDs []uuid.UUID
+	if err := config.DB.Unscoped().Model(&models.Complaint{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Pluck("id", &complaintIDs).Error; err != nil {
+		return 0, 0, err
+	}
+	complaints := 0
+	for _, id := range complaintIDs {
+		if err := PurgeComplaint(id); err != nil {
+			return complaints, 0, err
+		}
+		complaints++
+	}
+
+	var apologyIDs []uuid.UUID
+	if err := config.DB.Unscoped().Model(&models.Apology{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Pluck("id", &apologyIDs).Error; err != nil {
+		return complaints, 0, err
+	}
+	apologies := 0
+	for _, id := range apologyIDs {
+		if err := PurgeApology(id, nil, "system"); err != nil {
+			return complaints, apologies, err
+		}
+		apologies++
+	}
+	return complaints, apologies, nil
+}
+
+// PurgeComplaint hard-deletes a complaint with its child records, then removes its
+// attachments from Cloudinary and disk. Confidential access logs are kept as an audit trail.
+func PurgeComplaint(id uuid.UUID) error {
+	var complaint models.Complaint
+	if err := config.DB.Unscoped().Preload("Attachments").First(&complaint, "id = ?", id).Error; err != nil {
+		return err
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Delete child records explicitly to satisfy FK constraints
+		children := []interface{}{
+			&models.Attachment{}, &models.TimelineEntry{}, &models.ComplaintRevision{},
+			&models.ComplaintFeedback{}, &models.ComplaintWatcher{},
+		}
+		for _, child := range children {
+			if err := tx.Where("complaint_id = ?", id).Delete(child).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Exec("DELETE FROM complaint_tags WHERE complaint_id = ?", id).Error; err != nil {
+			return err
+		}
+		// Notifications are not FK-constrained, but clean them up if they reference this complaint
+		if err := tx.Where("related_id = ? AND related_type = ?", id, "complaint").Delete(&models.Notification{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("id = ?", id).Delete(&models.Complaint{}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	// Best-effort: remove attachments from Cloudinary and the local upload directory
+	publicIDs := []string{}
+	for _, a := range complaint.Attachments {
+		publicIDs = append(publicIDs, a.PublicID)
+	}
+	destroyCloudinaryAssets(publicIDs)
+	attachDir := filepath.Join("./uploads/attachments", id.String())
+	if stat, statErr := os.Stat(attachDir); statErr == nil && stat.IsDir() {
+		_ = os.RemoveAll(attachDir)
+	}
+	return nil
+}
+
+// PurgeApology hard-deletes an apology with its attachments, strike, guardian acknowledgement and notifications, then removes
+// the attachment files from Cloudinary and disk. Its history is kept and closed with a purged event.
+func PurgeApology(id uuid.UUID, actorID *uuid.UUID, actorRole string) error {
+	var apology models.Apology
+	if err := config.DB.Unscoped().Preload("Attachments").First(&apology, "id = ?", id).Error; err != nil {
+		return err
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("apology_id = ?", id).Delete(&models.ApologyAttachment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("apology_id = ?", id).Delete(&models.DisciplinaryStrike{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("apology_id = ?", id).Delete(&models.GuardianAcknowledgement{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("related_id = ? AND related_type = ?", id, "apology").Delete(&models.Notification{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("id = ?", id).Delete(&models.Apology{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.ApologyEvent{
+			ID: uuid.New(), ApologyID: id, EventType: models.ApologyEventPurged,
+			FromStatus: apology.Status, ActorID: actorID, ActorRole: actorRole,
+			Comment: "Permanently deleted",
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	publicIDs := []string{}
+	for _, a := range apology.Attachments {
+		publicIDs = append(publicIDs, a.PublicID)
+	}
+	destroyCloudinaryAssets(publicIDs)
+	// Local fallbacks are stored as ./uploads/apologies/<apologyID>_<filename>
+	if matches, err := filepath.Glob(filepath.Join("./uploads/apologies", id.String()+"_*")); err == nil {
+		for _, m := range matches {
+			_ = os.Remove(m)
+		}
+	}
+	return nil
+}
+
+func destroyCloudinaryAssets(publicIDs []string) {
+	if len(publicIDs) == 0 {
+		return
+	}
+	cld, err := helpers.InitCloudinary()
+	if err != nil {
+		return
+	}
+	for _, pid := range publicIDs {
+		if pid != "" {
+			_ = cld.Destroy(pid)
+		}
+	}
+}
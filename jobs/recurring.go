@@ -0,0 +1,192 @@
+package jobs
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/aditisaxena259/mental-health-be/config"
+	"github.com/aditisaxena259/mental-health-be/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RecurringConfig controls the recurring-issue analyzer.
+// Env: RECURRING_WINDOW_DAYS (default 90), RECURRING_THRESHOLD (default 3),
+// RECURRING_SCAN_INTERVAL (Go duration, default 6h).
+type RecurringConfig struct {
+	WindowDays int
+	Threshold  int
+	Interval   time.Duration
+}
+
+func RecurringConfigFromEnv() RecurringConfig {
+	cfg := RecurringConfig{WindowDays: 90, Threshold: 3, Interval: 6 * time.Hour}
+	if v, err := strconv.Atoi(os.Getenv("RECURRING_WINDOW_DAYS")); err == nil && v > 0 {
+		cfg.WindowDays = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("RECURRING_THRESHOLD")); err == nil && v > 1 {
+		cfg.Threshold = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("RECURRING_SCAN_INTERVAL")); err == nil && v > 0 {
+		cfg.Interval = v
+	}
+	return cfg
+}
+
+// StartRecurringIssueAnalyzer runs AnalyzeRecurringIssues once at startup and then on every tick.
+func StartRecurringIssueAnalyzer(cfg RecurringConfig) {
+	go func() {
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+		for {
+			if n, err := AnalyzeRecurringIssues(cfg); err != nil {
+				log.Println("⚠️ Recurring-issue analysis failed:", err)
+			} else if n > 0 {
+				log.Printf("🔁 Recurring-issue analysis raised %d new issue(s)", n)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+type recurrenceGroup struct {
+	RoomID  uuid.UUID
+	Block   string
+	Type    string
+	AssetID *uuid.UUID
+	Count   int
+	First   time.Time
+	Last    time.Time
+}
+
+// AnalyzeRecurringIssues groups recent complaints by room and type, and additionally by asset
+// where one is linked, and raises a RecurringIssue for every group at or above the threshold.
+// Confidential complaints are not counted, as an issue raised on their room would point at the filer.
+// An existing open or acknowledged issue for the same group is refreshed instead of duplicated,
+// and once an issue is closed only complaints filed after it count towards raising another.
+// Returns the number of newly created issues.
+func AnalyzeRecurringIssues(cfg RecurringConfig) (int, error) {
+	var groups []recurrenceGroup
+	err := config.DB.Raw(`
+		SELECT complaints.room_id, rooms.block, complaints.type, complaints.asset_id,
+			COUNT(*) AS count, MIN(complaints.created_at) AS first, MAX(complaints.created_at) AS last
+		FROM complaints
+		JOIN rooms ON rooms.id = complaints.room_id
+		WHERE complaints.room_id IS NOT NULL
+			AND complaints.deleted_at IS NULL
+			AND NOT complaints.is_confidential
+			AND complaints.status <> ?
+			AND complaints.created_at >= NOW() - make_interval(days => ?)
+		GROUP BY GROUPING SETS (
+			(complaints.room_id, rooms.block, complaints.type),
+			(complaints.room_id, rooms.block, complaints.type, complaints.asset_id)
+		)
+		HAVING COUNT(*) >= ?
+			AND (GROUPING(complaints.asset_id) = 1 OR complaints.asset_id IS NOT NULL)`,
+		models.Withdrawn, cfg.WindowDays, cfg.Threshold).Scan(&groups).Error
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for _, g := range groups {
+		var issue models.RecurringIssue
+		if recurrenceIssues(g).Where("status IN ?", []models.RecurringIssueStatus{models.RecurringOpen, models.RecurringAcknowledged}).
+			First(&issue).Error == nil {
+			config.DB.Model(&issue).Updates(map[string]interface{}{
+				"complaint_count": g.Count,
+				"last_seen_at":    g.Last,
+				"window_days":     cfg.WindowDays,
+			})
+			continue
+		}
+
+		// complaints already covered by a closed issue do not count towards a new one
+		var closed models.RecurringIssue
+		if recurrenceIssues(g).Where("status = ?", models.RecurringClosed).Order("last_seen_at DESC").First(&closed).Error == nil {
+			if !g.Last.After(closed.LastSeenAt) {
+				continue
+			}
+			fresh, err := recountSince(cfg, g, closed.LastSeenAt)
+			if err != nil {
+				return created, err
+			}
+			if fresh.Count < cfg.Threshold {
+				continue
+			}
+			g.Count, g.First = fresh.Count, fresh.First
+		}
+
+		issue = models.RecurringIssue{
+			ID:             uuid.New(),
+			Block:          g.Block,
+			RoomID:         g.RoomID,
+			AssetID:        g.AssetID,
+			Type:           models.ComplaintType(g.Type),
+			ComplaintCount: g.Count,
+			WindowDays:     cfg.WindowDays,
+			FirstSeenAt:    g.First,
+			LastSeenAt:     g.Last,
+			Status:         models.RecurringOpen,
+		}
+		if err := config.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&issue).Error; err != nil {
+				return err
+			}
+			return notifyChiefsOfRecurringIssue(tx, issue)
+		}); err != nil {
+			return created, err
+		}
+		created++
+	}
+	if created > 0 {
+		WakeOutbox()
+	}
+	return created, nil
+}
+
+// recurrenceIssues scopes recurring issues to the room, type and asset of g.
+func recurrenceIssues(g recurrenceGroup) *gorm.DB {
+	query := config.DB.Where("room_id = ? AND type = ?", g.RoomID, g.Type)
+	if g.AssetID != nil {
+		return query.Where("asset_id = ?", *g.AssetID)
+	}
+	return query.Where("asset_id IS NULL")
+}
+
+// recountSince counts the complaints of g filed within the window and after since.
+func recountSince(cfg RecurringConfig, g recurrenceGroup, since time.Time) (recurrenceGroup, error) {
+	query := config.DB.Model(&models.Complaint{}).
+		Select("COUNT(*) AS count, MIN(created_at) AS first, MAX(created_at) AS last").
+		Where("room_id = ? AND type = ? AND NOT is_confidential AND status <> ?", g.RoomID, g.Type, models.Withdrawn).
+		Where("created_at >= NOW() - make_interval(days => ?) AND created_at > ?", cfg.WindowDays, since)
+	if g.AssetID != nil {
+		query = query.Where("asset_id = ?", *g.AssetID)
+	}
+	var fresh recurrenceGroup
+	err := query.Scan(&fresh).Error
+	return fresh, err
+}
+
+func notifyChiefsOfRecurringIssue(tx *gorm.DB, issue models.RecurringIssue) error {
+	var room models.Room
+	tx.First(&room, "id = ?", issue.RoomID)
+	subject := fmt.Sprintf("%s complaints in room %s%s", issue.Type, room.Block, room.Number)
+	if issue.AssetID != nil {
+		var asset models.Asset
+		if tx.First(&asset, "id = ?", *issue.AssetID).Error == nil {
+			subject = fmt.Sprintf("%s complaints about %s in room %s%s", issue.Type, asset.Label, room.Block, room.Number)
+		}
+	}
+	return EnqueueNotification(tx, models.NotificationFanout{
+		Chiefs:      true,
+		Title:       "Recurring Issue Detected",
+		Message:     fmt.Sprintf("%d %s within %d days", issue.ComplaintCount, subject, issue.WindowDays),
+		Type:        "warning",
+		RelatedID:   &issue.ID,
+		RelatedType: "recurring_issue",
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"log"
 
 	"github.com/aditisaxena259/mental-health-be/config"
+	"github.com/aditisaxena259/mental-health-be/controllers"
+	"github.com/aditisaxena259/mental-health-be/jobs"
 	"github.com/aditisaxena259/mental-health-be/models"
 	"github.com/aditisaxena259/mental-health-be/routes"
 	"github.com/gofiber/fiber/v2"
@@ -27,14 +29,22 @@ func main() {
 	models.SeedData()
 	log.Println("📦 Database migrations completed successfully!")
 
-	// Initialize Fiber app
-	app := fiber.New()
+	// Background jobs
+	jobs.StartRecurringIssueAnalyzer(jobs.RecurringConfigFromEnv())
+	jobs.StartTrashPurger(jobs.TrashConfigFromEnv())
+	jobs.StartGuardianAckMonitor(jobs.GuardianConfigFromEnv())
+	jobs.StartOutboxWorker(jobs.OutboxConfigFromEnv())
+	jobs.StartNotificationListener(jobs.NotificationListenerConfigFromEnv())
+
+	// Initialize Fiber app; the body limit follows the attachment limits so uploads are not cut off
+	app := fiber.New(fiber.Config{BodyLimit: controllers.RequestBodyLimit()})
 
 	// Enable CORS Middleware
 	app.Use(cors.New(cors.Config{
-		AllowOrigins: "*", // frontend origin
-		AllowMethods: "GET,POST,PUT,DELETE,OPTIONS",
-		AllowHeaders: "Origin, Content-Type, Accept, Authorization",
+		AllowOrigins:  "*", // frontend origin
+		AllowMethods:  "GET,POST,PUT,DELETE,OPTIONS",
+		AllowHeaders:  "Origin, Content-Type, Accept, Authorization, If-Match",
+		ExposeHeaders: "ETag",
 	}))
 
 	// Serve local uploads (used when Cloudinary is not configured)
@@ -29,6 +29,18 @@ func ProtectRoute(c *fiber.Ctx) error {
 	c.Locals("role", claims["role"])
 	return c.Next()
 }
+
+// TokenFromQuery lets clients that cannot set headers, such as the browser EventSource, pass
+// the JWT as ?access_token=. It only fills in a missing Authorization header for ProtectRoute.
+func TokenFromQuery(c *fiber.Ctx) error {
+	if c.Get("Authorization") == "" {
+		if token := c.Query("access_token"); token != "" {
+			c.Request().Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+	return c.Next()
+}
+
 func RequireRole(roles ...string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		role, _ := c.Locals("role").(string)
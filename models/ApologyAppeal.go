@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AppealStatus string
+
+const (
+	AppealPending     AppealStatus = "pending"
+	AppealUnderReview AppealStatus = "under_review"
+	// AppealUpheld keeps the rejection; AppealOverturned accepts the apology
+	AppealUpheld     AppealStatus = "upheld"
+	AppealOverturned AppealStatus = "overturned"
+)
+
+// appealTransitions lists the statuses each appeal status may move to. Upheld and overturned are final.
+var appealTransitions = map[AppealStatus][]AppealStatus{
+	AppealPending:     {AppealUnderReview, AppealUpheld, AppealOverturned},
+	AppealUnderReview: {AppealUpheld, AppealOverturned},
+	AppealUpheld:      {},
+	AppealOverturned:  {},
+}
+
+// IsValid reports whether s is a known appeal status.
+func (s AppealStatus) IsValid() bool {
+	_, ok := appealTransitions[s]
+	return ok
+}
+
+// CanTransitionTo reports whether an appeal in status s may move to next.
+func (s AppealStatus) CanTransitionTo(next AppealStatus) bool {
+	for _, allowed := range appealTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// ApologyAppeal is a student's appeal to the chief admin against a rejected apology.
+// An apology can be appealed once.
+type ApologyAppeal struct {
+	ID              uuid.UUID    `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ApologyID       uuid.UUID    `gorm:"type:uuid;not null;uniqueIndex" json:"apology_id"`
+	StudentID       uuid.UUID    `gorm:"type:uuid;not null;index" json:"student_id"`
+	Reason          string       `gorm:"type:text;not null" json:"reason"`
+	Status          AppealStatus `gorm:"type:text;not null;default:'pending'" json:"status"`
+	DecidedByID     *uuid.UUID   `gorm:"type:uuid" json:"decided_by_id,omitempty"`
+	DecisionComment string       `gorm:"type:text" json:"decision_comment"`
+	CreatedAt       time.Time    `gorm:"autoCreateTime" json:"created_at"`
+	DecidedAt       *time.Time   `json:"decided_at,omitempty"`
+
+	History []ApologyAppealEvent `gorm:"foreignKey:AppealID;constraint:OnDelete:CASCADE;" json:"history"`
+}
+
+func (ApologyAppeal) TableName() string {
+	return "apology_appeals"
+}
+
+// ApologyAppealEvent records one status change of an appeal, starting with its filing.
+type ApologyAppealEvent struct {
+	ID         uuid.UUID    `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	AppealID   uuid.UUID    `gorm:"type:uuid;not null;index" json:"appeal_id"`
+	FromStatus AppealStatus `gorm:"type:text" json:"from_status,omitempty"`
+	ToStatus   AppealStatus `gorm:"type:text;not null" json:"to_status"`
+	ActorID    uuid.UUID    `gorm:"type:uuid;not null" json:"actor_id"`
+	ActorRole  string       `gorm:"type:text;not null" json:"actor_role"`
+	Comment    string       `gorm:"type:text" json:"comment"`
+	CreatedAt  time.Time    `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (ApologyAppealEvent) TableName() string {
+	return "apology_appeal_events"
+}
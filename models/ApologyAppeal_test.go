@@ -0,0 +1,25 @@
+package models
+
+import "testing"
+
+func TestAppealStatusCanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from, to AppealStatus
+		want     bool
+	}{
+		{AppealPending, AppealUnderReview, true},
+		{AppealPending, AppealUpheld, true},
+		{AppealPending, AppealOverturned, true},
+		{AppealUnderReview, AppealUpheld, true},
+		{AppealUnderReview, AppealOverturned, true},
+		{AppealUnderReview, AppealPending, false},
+		{AppealUpheld, AppealOverturned, false},
+		{AppealOverturned, AppealUpheld, false},
+		{"withdrawn", AppealPending, false},
+	}
+	for _, tt := range tests {
+		if got := tt.from.CanTransitionTo(tt.to); got != tt.want {
+			t.Errorf("%q -> %q = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
@@ -9,6 +9,8 @@ type ApologyAttachment struct {
 	FileURL   string    `json:"file_url"`
 	PublicID  string    `json:"public_id"`
 	Size      string    `json:"size"`
+	// ContentType is detected from the file content: image/jpeg or application/pdf
+	ContentType string `gorm:"type:text" json:"content_type"`
 }
 
 func (ApologyAttachment) TableName() string {
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ApologyEventType string
+
+const (
+	ApologyEventSubmitted    ApologyEventType = "submitted"
+	ApologyEventEdited       ApologyEventType = "edited"
+	ApologyEventWithdrawn    ApologyEventType = "withdrawn"
+	ApologyEventStatusChange ApologyEventType = "status_change"
+	ApologyEventGuardian     ApologyEventType = "guardian"
+	ApologyEventDeleted      ApologyEventType = "deleted"
+	ApologyEventRestored     ApologyEventType = "restored"
+	ApologyEventPurged       ApologyEventType = "purged"
+)
+
+// ApologyEvent is one entry of an apology's history. Events are not FK-bound to the apology so
+// the trail survives a purge. ActorID is empty for guardian and system actions.
+type ApologyEvent struct {
+	ID         uuid.UUID        `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ApologyID  uuid.UUID        `gorm:"type:uuid;not null;index" json:"apology_id"`
+	EventType  ApologyEventType `gorm:"type:text;not null" json:"event_type"`
+	FromStatus ApologyStatus    `gorm:"type:text" json:"from_status,omitempty"`
+	ToStatus   ApologyStatus    `gorm:"type:text" json:"to_status,omitempty"`
+	ActorID    *uuid.UUID       `gorm:"type:uuid" json:"actor_id,omitempty"`
+	ActorRole  string           `gorm:"type:text" json:"actor_role"`
+	Comment    string           `gorm:"type:text" json:"comment"`
+	// Previous snapshots the edited fields as they were before an edit
+	ChangedFields string                 `gorm:"type:text" json:"changed_fields,omitempty"`
+	Previous      map[string]interface{} `gorm:"type:jsonb;serializer:json" json:"previous,omitempty"`
+	CreatedAt     time.Time              `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (ApologyEvent) TableName() string {
+	return "apology_events"
+}
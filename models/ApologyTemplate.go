@@ -0,0 +1,206 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TemplateFieldKind string
+
+const (
+	FieldText    TemplateFieldKind = "text"
+	FieldDate    TemplateFieldKind = "date"    // YYYY-MM-DD
+	FieldList    TemplateFieldKind = "list"    // e.g. witnesses
+	FieldBoolean TemplateFieldKind = "boolean" // a required boolean must be true (an acknowledgement)
+)
+
+// ApologyTemplateField is one structured field a student fills in for an apology type.
+type ApologyTemplateField struct {
+	Key      string            `json:"key"`
+	Label    string            `json:"label"`
+	Kind     TemplateFieldKind `json:"kind"`
+	Required bool              `json:"required"`
+}
+
+// ApologyFields holds the structured values of a submitted apology, keyed by field key.
+type ApologyFields map[string]interface{}
+
+// ApologyTemplate defines the structured fields and the letter layout for one apology type.
+// Body may reference any field as {{key}} as well as {{student_name}}, {{student_identifier}},
+// {{block}}, {{room_no}}, {{date}} and {{message}}.
+type ApologyTemplate struct {
+	ID          uuid.UUID              `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ApologyType ApologyType            `gorm:"type:text;not null;uniqueIndex" json:"type"`
+	Title       string                 `gorm:"type:text;not null" json:"title"`
+	Body        string                 `gorm:"type:text;not null" json:"body"`
+	Fields      []ApologyTemplateField `gorm:"type:jsonb;serializer:json" json:"fields"`
+	IsActive    bool                   `gorm:"not null;default:true" json:"is_active"`
+	UpdatedByID *uuid.UUID             `gorm:"type:uuid" json:"updated_by_id,omitempty"`
+	CreatedAt   time.Time              `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time              `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (ApologyTemplate) TableName() string {
+	return "apology_templates"
+}
+
+var templateFieldKey = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+
+// reservedTemplateKeys are filled in from the student record and cannot be used as field keys.
+var reservedTemplateKeys = map[string]bool{
+	"student_name": true, "student_identifier": true, "block": true, "room_no": true, "date": true, "message": true,
+}
+
+// CheckDefinition validates the template's own field definitions.
+func (t ApologyTemplate) CheckDefinition() error {
+	if strings.TrimSpace(t.Title) == "" || strings.TrimSpace(t.Body) == "" {
+		return fmt.Errorf("title and body are required")
+	}
+	seen := map[string]bool{}
+	for _, f := range t.Fields {
+		if !templateFieldKey.MatchString(f.Key) {
+			return fmt.Errorf("field key %q must be lowercase letters, digits and underscores", f.Key)
+		}
+		if reservedTemplateKeys[f.Key] {
+			return fmt.Errorf("field key %q is reserved", f.Key)
+		}
+		if seen[f.Key] {
+			return fmt.Errorf("duplicate field key %q", f.Key)
+		}
+		seen[f.Key] = true
+		switch f.Kind {
+		case FieldText, FieldDate, FieldList, FieldBoolean:
+		default:
+			return fmt.Errorf("field %q has unknown kind %q", f.Key, f.Kind)
+		}
+	}
+	return nil
+}
+
+// Validate checks submitted values against the template and returns them normalised: text
+// trimmed, dates as YYYY-MM-DD, lists as non-empty strings. Unknown keys are rejected.
+func (t ApologyTemplate) Validate(values map[string]interface{}) (ApologyFields, error) {
+	known := map[string]bool{}
+	out := ApologyFields{}
+	for _, f := range t.Fields {
+		known[f.Key] = true
+		raw, present := values[f.Key]
+		switch f.Kind {
+		case FieldText:
+			s, ok := raw.(string)
+			if present && raw != nil && !ok {
+				return nil, fmt.Errorf("%s must be text", f.Label)
+			}
+			if s = strings.TrimSpace(s); s != "" {
+				out[f.Key] = s
+			} else if f.Required {
+				return nil, fmt.Errorf("%s is required", f.Label)
+			}
+		case FieldDate:
+			s, _ := raw.(string)
+			if s = strings.TrimSpace(s); s == "" {
+				if f.Required {
+					return nil, fmt.Errorf("%s is required", f.Label)
+				}
+				continue
+			}
+			d, err := time.Parse("2006-01-02", s)
+			if err != nil {
+				return nil, fmt.Errorf("%s must be a date (YYYY-MM-DD)", f.Label)
+			}
+			if d.After(time.Now()) {
+				return nil, fmt.Errorf("%s cannot be in the future", f.Label)
+			}
+			out[f.Key] = s
+		case FieldList:
+			items := []string{}
+			switch v := raw.(type) {
+			case nil:
+			case string:
+				// comma separated, as sent by multipart forms
+				for _, item := range strings.Split(v, ",") {
+					if item = strings.TrimSpace(item); item != "" {
+						items = append(items, item)
+					}
+				}
+			case []interface{}:
+				for _, item := range v {
+					s, ok := item.(string)
+					if !ok {
+						return nil, fmt.Errorf("%s must be a list of text", f.Label)
+					}
+					if s = strings.TrimSpace(s); s != "" {
+						items = append(items, s)
+					}
+				}
+			default:
+				return nil, fmt.Errorf("%s must be a list of text", f.Label)
+			}
+			if len(items) == 0 && f.Required {
+				return nil, fmt.Errorf("%s is required", f.Label)
+			}
+			if len(items) > 0 {
+				out[f.Key] = items
+			}
+		case FieldBoolean:
+			var b bool
+			switch v := raw.(type) {
+			case nil:
+			case bool:
+				b = v
+			case string:
+				b = v == "true"
+			default:
+				return nil, fmt.Errorf("%s must be true or false", f.Label)
+			}
+			if f.Required && !b {
+				return nil, fmt.Errorf("%s must be confirmed", f.Label)
+			}
+			out[f.Key] = b
+		}
+	}
+	for key := range values {
+		if !known[key] {
+			return nil, fmt.Errorf("unknown field %q", key)
+		}
+	}
+	return out, nil
+}
+
+// Render fills the template body with the student's details and submitted values.
+func (t ApologyTemplate) Render(vars map[string]string, values ApologyFields) string {
+	pairs := []string{}
+	for key, v := range vars {
+		pairs = append(pairs, "{{"+key+"}}", v)
+	}
+	for _, f := range t.Fields {
+		var text string
+		switch v := values[f.Key].(type) {
+		case string:
+			text = v
+		case []string:
+			text = strings.Join(v, ", ")
+		case []interface{}:
+			// lists read back from the database
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				items = append(items, fmt.Sprint(item))
+			}
+			text = strings.Join(items, ", ")
+		case bool:
+			text = "No"
+			if v {
+				text = "Yes"
+			}
+		}
+		if text == "" {
+			text = "-"
+		}
+		pairs = append(pairs, "{{"+f.Key+"}}", text)
+	}
+	return strings.NewReplacer(pairs...).Replace(t.Body)
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func misconductTemplate() ApologyTemplate {
+	return ApologyTemplate{
+		Title: "Misconduct",
+		Body:  "I, {{student_name}} of block {{block}}, apologise for the incident on {{incident_date}}. Witnesses: {{witnesses}}. Details: {{details}}. Understood rules: {{ack}}.",
+		Fields: []ApologyTemplateField{
+			{Key: "incident_date", Label: "Incident date", Kind: FieldDate, Required: true},
+			{Key: "witnesses", Label: "Witnesses", Kind: FieldList},
+			{Key: "details", Label: "Details", Kind: FieldText},
+			{Key: "ack", Label: "Acknowledgement", Kind: FieldBoolean, Required: true},
+		},
+	}
+}
+
+func TestApologyTemplateValidate(t *testing.T) {
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	tests := []struct {
+		name    string
+		values  map[string]interface{}
+		want    ApologyFields
+		wantErr string
+	}{
+		{
+			name:   "normalises values",
+			values: map[string]interface{}{"incident_date": " 2024-05-01 ", "witnesses": []interface{}{" Asha ", "", "Ravi"}, "details": "  late  ", "ack": true},
+			want:   ApologyFields{"incident_date": "2024-05-01", "witnesses": []string{"Asha", "Ravi"}, "details": "late", "ack": true},
+		},
+		{
+			name:   "form values",
+			values: map[string]interface{}{"incident_date": "2024-05-01", "witnesses": "Asha, Ravi,", "ack": "true"},
+			want:   ApologyFields{"incident_date": "2024-05-01", "witnesses": []string{"Asha", "Ravi"}, "ack": true},
+		},
+		{name: "missing required date", values: map[string]interface{}{"ack": true}, wantErr: "Incident date is required"},
+		{name: "malformed date", values: map[string]interface{}{"incident_date": "01/05/2024", "ack": true}, wantErr: "Incident date must be a date (YYYY-MM-DD)"},
+		{name: "future date", values: map[string]interface{}{"incident_date": tomorrow, "ack": true}, wantErr: "Incident date cannot be in the future"},
+		{name: "unconfirmed acknowledgement", values: map[string]interface{}{"incident_date": "2024-05-01", "ack": false}, wantErr: "Acknowledgement must be confirmed"},
+		{name: "text of wrong type", values: map[string]interface{}{"incident_date": "2024-05-01", "ack": true, "details": 3.0}, wantErr: "Details must be text"},
+		{name: "list of wrong type", values: map[string]interface{}{"incident_date": "2024-05-01", "ack": true, "witnesses": []interface{}{1.0}}, wantErr: "Witnesses must be a list of text"},
+		{name: "unknown key", values: map[string]interface{}{"incident_date": "2024-05-01", "ack": true, "room": "A1"}, wantErr: `unknown field "room"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := misconductTemplate().Validate(tt.values)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("values = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApologyTemplateRender(t *testing.T) {
+	vars := map[string]string{"student_name": "Asha", "block": "B"}
+	tests := []struct {
+		name   string
+		values ApologyFields
+		want   string
+	}{
+		{
+			name:   "validated values",
+			values: ApologyFields{"incident_date": "2024-05-01", "witnesses": []string{"Ravi", "Meera"}, "details": "late", "ack": true},
+			want:   "I, Asha of block B, apologise for the incident on 2024-05-01. Witnesses: Ravi, Meera. Details: late. Understood rules: Yes.",
+		},
+		{
+			name:   "values read back from the database",
+			values: ApologyFields{"incident_date": "2024-05-01", "witnesses": []interface{}{"Ravi"}, "ack": false},
+			want:   "I, Asha of block B, apologise for the incident on 2024-05-01. Witnesses: Ravi. Details: -. Understood rules: No.",
+		},
+		{
+			name:   "missing values",
+			values: ApologyFields{},
+			want:   "I, Asha of block B, apologise for the incident on -. Witnesses: -. Details: -. Understood rules: -.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := misconductTemplate().Render(vars, tt.values); got != tt.want {
+				t.Errorf("Render() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -5,8 +5,11 @@ import (
 
 	"github.com/aditisaxena259/mental-health-be/config"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+// ComplaintType is the key of a ComplaintCategory. The constants below are the
+// categories that existed before categories moved into the complaint_categories table.
 type ComplaintType string
 
 const (
@@ -24,8 +27,35 @@ const (
 	Open       ComplaintStatus = "open"
 	InProgress ComplaintStatus = "inprogress"
 	Resolved   ComplaintStatus = "resolved"
+	// Withdrawn is set when the student pulls the complaint back before it is resolved
+	Withdrawn ComplaintStatus = "withdrawn"
 )
 
+// complaintTransitions lists the statuses each status may move to. Withdrawn is terminal;
+// resolved complaints can be reopened by moving them back to in progress.
+var complaintTransitions = map[ComplaintStatus][]ComplaintStatus{
+	Open:       {InProgress, Resolved},
+	InProgress: {Open, Resolved},
+	Resolved:   {InProgress},
+	Withdrawn:  {},
+}
+
+// IsValid reports whether s is a known complaint status.
+func (s ComplaintStatus) IsValid() bool {
+	_, ok := complaintTransitions[s]
+	return ok
+}
+
+// CanTransitionTo reports whether a complaint in status s may move to next.
+func (s ComplaintStatus) CanTransitionTo(next ComplaintStatus) bool {
+	for _, allowed := range complaintTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
 type ComplaintPriority string
 
 const (
@@ -40,28 +70,44 @@ type Complaint struct {
 	UserID uuid.UUID `gorm:"type:uuid;not null"`
 	// StudentIdentifier links the complaint to the external student identifier (e.g. roll number)
 	StudentIdentifier string            `gorm:"type:text;index" json:"student_identifier"`
-	Type              ComplaintType     `gorm:"type:complaint_type;not null"`
+	Type              ComplaintType     `gorm:"type:text;not null"`
 	Description       string            `gorm:"type:text;not null"`
 	Priority          ComplaintPriority `gorm:"type:text;default:'medium'" json:"priority"`
 	Status            ComplaintStatus   `gorm:"type:status_type;default:'open'"`
 	CreatedAt         time.Time         `gorm:"autoCreateTime"`
+	// CategoryID/SubcategoryID reference complaint_categories; Type keeps the category key
+	CategoryID    *uuid.UUID `gorm:"type:uuid;index" json:"category_id"`
+	SubcategoryID *uuid.UUID `gorm:"type:uuid;index" json:"subcategory_id,omitempty"`
+	// RoomID/AssetID optionally point at the room and fixture the complaint is about
+	RoomID  *uuid.UUID `gorm:"type:uuid;index" json:"room_id,omitempty"`
+	AssetID *uuid.UUID `gorm:"type:uuid;index" json:"asset_id,omitempty"`
+	// DueAt is derived from the category SLA at creation time
+	DueAt *time.Time `json:"due_at,omitempty"`
+	// ImpactScore starts at 1 and grows with every student who "+1"s the complaint
+	ImpactScore int `gorm:"not null;default:1" json:"impact_score"`
+	// IsConfidential hides the filing student's identity and room from staff; see ConfidentialAccessLog
+	IsConfidential bool `gorm:"not null;default:false" json:"is_confidential"`
+	// Version increments on every update and is exposed as the ETag for optimistic locking
+	Version int `gorm:"not null;default:1" json:"version"`
+	// AssignedToID is the staff member currently responsible for the complaint
+	AssignedToID *uuid.UUID `gorm:"type:uuid;index" json:"assigned_to_id"`
+	// DeletedAt puts the complaint in the trash; jobs.PurgeExpiredTrash hard-deletes it after the retention window
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+	DeletedByID *uuid.UUID     `gorm:"type:uuid" json:"deleted_by_id,omitempty"`
 
 	User User `gorm:"foreignKey:UserID;references:ID" json:"user"`
 	// Fix relationship: UserID (complaint) -> UserID (student_models)
 	Student     StudentModel    `gorm:"foreignKey:UserID;references:UserID" json:"student"`
 	Attachments []Attachment    `gorm:"foreignKey:ComplaintID" json:"attachments"`
 	Timeline    []TimelineEntry `gorm:"foreignKey:ComplaintID" json:"timeline"`
+	Room        *Room           `gorm:"foreignKey:RoomID" json:"room,omitempty"`
+	Asset       *Asset          `gorm:"foreignKey:AssetID" json:"asset,omitempty"`
+	Tags        []Tag           `gorm:"many2many:complaint_tags;" json:"tags"`
+	// Feedback is the student's satisfaction rating, present once submitted after resolution
+	Feedback *ComplaintFeedback `gorm:"foreignKey:ComplaintID" json:"feedback,omitempty"`
 }
 
 func MigrateDatabase() {
-	config.DB.Exec(`DO $$ BEGIN 
-        IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'complaint_type') THEN 
-            CREATE TYPE complaint_type AS ENUM (
-                'roommate', 'plumbing', 'cleanliness', 'electricity', 'Lost and Found', 'Other Issues'
-            ); 
-        END IF; 
-    END $$;`)
-
 	config.DB.Exec(`DO $$ BEGIN 
         IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'status_type') THEN 
             CREATE TYPE status_type AS ENUM ('open', 'inprogress', 'resolved'); 
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ComplaintCategory is a data-driven complaint category. Top-level categories have no
+// ParentID; subcategories point at their parent. Complaint.Type stores the category Key.
+type ComplaintCategory struct {
+	ID       uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Key      string     `gorm:"type:text;not null;uniqueIndex" json:"key"`
+	Name     string     `gorm:"type:text;not null" json:"name"`
+	ParentID *uuid.UUID `gorm:"type:uuid;index" json:"parent_id,omitempty"`
+	// DefaultPriority applies when the student doesn't pick one
+	DefaultPriority ComplaintPriority `gorm:"type:text;not null;default:'medium'" json:"default_priority"`
+	// SLAHours is the target time to resolution; 0 means no SLA
+	SLAHours int `gorm:"not null;default:0" json:"sla_hours"`
+	// AllowsConfidential marks sensitive categories that may be filed confidentially
+	AllowsConfidential bool      `gorm:"not null;default:false" json:"allows_confidential"`
+	IsActive           bool      `gorm:"not null;default:true" json:"is_active"`
+	SortOrder          int       `gorm:"not null;default:0" json:"sort_order"`
+	CreatedAt          time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt          time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Subcategories []ComplaintCategory `gorm:"foreignKey:ParentID" json:"subcategories,omitempty"`
+}
+
+func (ComplaintCategory) TableName() string {
+	return "complaint_categories"
+}
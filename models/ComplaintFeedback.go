@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ComplaintFeedback is the student's one-time satisfaction rating for a resolved complaint.
+type ComplaintFeedback struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ComplaintID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"complaint_id"`
+	StudentID   uuid.UUID `gorm:"type:uuid;not null;index" json:"student_id"`
+	Rating      int       `gorm:"not null;check:rating BETWEEN 1 AND 5" json:"rating"`
+	Comment     string    `gorm:"type:text" json:"comment"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (ComplaintFeedback) TableName() string {
+	return "complaint_feedback"
+}
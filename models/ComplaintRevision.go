@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ComplaintRevision keeps a snapshot of a complaint's editable fields as they were
+// before a student edit, so wardens can see how the complaint changed over time.
+type ComplaintRevision struct {
+	ID          uuid.UUID         `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ComplaintID uuid.UUID         `gorm:"type:uuid;not null;uniqueIndex:idx_complaint_revisions_number,priority:1" json:"complaint_id"`
+	EditorID    uuid.UUID         `gorm:"type:uuid;not null" json:"editor_id"`
+	Revision    int               `gorm:"not null;uniqueIndex:idx_complaint_revisions_number,priority:2" json:"revision"`
+	Title       string            `gorm:"type:text" json:"title"`
+	Description string            `gorm:"type:text" json:"description"`
+	Priority    ComplaintPriority `gorm:"type:text" json:"priority"`
+	// ChangedFields lists the fields the edit touched, comma separated (e.g. "title,priority")
+	ChangedFields string    `gorm:"type:text" json:"changed_fields"`
+	CreatedAt     time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (ComplaintRevision) TableName() string {
+	return "complaint_revisions"
+}
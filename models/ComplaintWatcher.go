@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WatchReason string
+
+const (
+	WatchFollow   WatchReason = "follow"   // explicitly followed
+	WatchPlusOne  WatchReason = "plus_one" // student reported the same issue with "+1"
+	WatchAssigned WatchReason = "assigned" // staff member assigned to the complaint
+)
+
+// ComplaintWatcher is a user following a complaint other than the student who filed it.
+// Watchers are notified on status changes and public timeline entries.
+type ComplaintWatcher struct {
+	ComplaintID uuid.UUID   `gorm:"type:uuid;primaryKey" json:"complaint_id"`
+	UserID      uuid.UUID   `gorm:"type:uuid;primaryKey;index" json:"user_id"`
+	Reason      WatchReason `gorm:"type:text;not null;default:'follow'" json:"reason"`
+	CreatedAt   time.Time   `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (ComplaintWatcher) TableName() string {
+	return "complaint_watchers"
+}
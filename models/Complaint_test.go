@@ -0,0 +1,28 @@
+package models
+
+import "testing"
+
+func TestComplaintStatusCanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from, to ComplaintStatus
+		want     bool
+	}{
+		{Open, InProgress, true},
+		{Open, Resolved, true},
+		{Open, Open, false},
+		{InProgress, Open, true},
+		{InProgress, Resolved, true},
+		{Resolved, InProgress, true},
+		{Resolved, Open, false},
+		{Open, Withdrawn, false},
+		{Withdrawn, Open, false},
+		{Withdrawn, InProgress, false},
+		{"closed", Open, false},
+		{Open, "closed", false},
+	}
+	for _, tt := range tests {
+		if got := tt.from.CanTransitionTo(tt.to); got != tt.want {
+			t.Errorf("%q -> %q = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ConfidentialAccessLog is the audit trail of every time a staff member revealed who filed
+// a confidential complaint.
+type ConfidentialAccessLog struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ComplaintID uuid.UUID `gorm:"type:uuid;not null;index" json:"complaint_id"`
+	ViewerID    uuid.UUID `gorm:"type:uuid;not null;index" json:"viewer_id"`
+	ViewerRole  RoleType  `gorm:"type:text;not null" json:"viewer_role"`
+	Reason      string    `gorm:"type:text;not null" json:"reason"`
+	IPAddress   string    `gorm:"type:text" json:"ip_address"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (ConfidentialAccessLog) TableName() string {
+	return "confidential_access_logs"
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// strikeSeverity is the number of points a decided apology adds to a student's disciplinary
+// record, by apology type and outcome. Types not listed (miscellaneous) never produce a strike.
+var strikeSeverity = map[ApologyType]map[ApologyStatus]int{
+	ApologyForOuting:     {ApologyAccepted: 1, ApologyRejected: 2},
+	ApologyForMisconduct: {ApologyAccepted: 2, ApologyRejected: 3},
+}
+
+// StrikeSeverity returns the points for an apology of type t decided as outcome, or 0 for none.
+func StrikeSeverity(t ApologyType, outcome ApologyStatus) int {
+	return strikeSeverity[t][outcome]
+}
+
+// DisciplinaryStrike is one entry of a student's disciplinary ledger. Each decided outing or
+// misconduct apology yields exactly one strike, re-derived if the outcome changes on appeal.
+// Strikes stop counting towards the student's active points once they expire.
+type DisciplinaryStrike struct {
+	ID        uuid.UUID     `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	StudentID uuid.UUID     `gorm:"type:uuid;not null;index" json:"student_id"`
+	ApologyID uuid.UUID     `gorm:"type:uuid;not null;uniqueIndex" json:"apology_id"`
+	Type      ApologyType   `gorm:"type:text;not null" json:"type"`
+	Outcome   ApologyStatus `gorm:"type:text;not null" json:"outcome"`
+	Severity  int           `gorm:"not null" json:"severity"`
+	ExpiresAt time.Time     `gorm:"not null;index" json:"expires_at"`
+	CreatedAt time.Time     `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time     `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (DisciplinaryStrike) TableName() string {
+	return "disciplinary_strikes"
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type GuardianAckStatus string
+
+const (
+	GuardianAckPending      GuardianAckStatus = "pending"
+	GuardianAckAcknowledged GuardianAckStatus = "acknowledged"
+	GuardianAckExpired      GuardianAckStatus = "expired"
+	// GuardianAckUnreachable: the student has no guardian contact on file, so no link was sent
+	GuardianAckUnreachable GuardianAckStatus = "unreachable"
+)
+
+// GuardianAcknowledgement records the request for a guardian to acknowledge an apology. The
+// guardian receives a signed one-time link; LinkNonce is rotated on resend so older links stop working.
+type GuardianAcknowledgement struct {
+	ID        uuid.UUID         `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ApologyID uuid.UUID         `gorm:"type:uuid;not null;uniqueIndex" json:"apology_id"`
+	StudentID uuid.UUID         `gorm:"type:uuid;not null;index" json:"student_id"`
+	Status    GuardianAckStatus `gorm:"type:text;not null;default:'pending';index" json:"status"`
+
+	// Contact the link was sent to, copied from the student record at the time
+	GuardianName  string `gorm:"type:text" json:"guardian_name"`
+	GuardianEmail string `gorm:"type:text" json:"guardian_email"`
+	GuardianPhone string `gorm:"type:text" json:"guardian_phone"`
+
+	LinkNonce      string     `gorm:"type:text;not null" json:"-"`
+	SentAt         *time.Time `json:"sent_at,omitempty"`
+	ExpiresAt      time.Time  `gorm:"not null;index" json:"expires_at"`
+	RemindersSent  int        `gorm:"not null;default:0" json:"reminders_sent"`
+	LastReminderAt *time.Time `json:"last_reminder_at,omitempty"`
+
+	AcknowledgedAt   *time.Time `json:"acknowledged_at,omitempty"`
+	AcknowledgedName string     `gorm:"type:text" json:"acknowledged_name,omitempty"`
+	GuardianComment  string     `gorm:"type:text" json:"guardian_comment,omitempty"`
+	AcknowledgedIP   string     `gorm:"type:text" json:"acknowledged_ip,omitempty"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (GuardianAcknowledgement) TableName() string {
+	return "guardian_acknowledgements"
+}
@@ -19,3 +19,7 @@ type Notification struct {
 	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt   time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
 }
+
+// NotificationChannel is the Postgres LISTEN/NOTIFY channel a trigger on notifications
+// publishes to on every insert, read-state change and delete (see AutoMigrateAll).
+const NotificationChannel = "notification_events"
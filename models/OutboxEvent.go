@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OutboxStatus string
+
+const (
+	OutboxPending OutboxStatus = "pending"
+	OutboxDone    OutboxStatus = "done"
+	// OutboxDead events ran out of attempts (or cannot be handled) and wait for a manual retry
+	OutboxDead OutboxStatus = "dead"
+)
+
+// OutboxKindNotify fans a notification out to the recipients described by a NotificationFanout.
+const OutboxKindNotify = "notify"
+
+// OutboxEvent is a side effect written in the same transaction as the change that causes it and
+// carried out afterwards by jobs.StartOutboxWorker, so the two succeed or fail together.
+type OutboxEvent struct {
+	ID            uuid.UUID              `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Kind          string                 `gorm:"type:text;not null" json:"kind"`
+	Payload       map[string]interface{} `gorm:"type:jsonb;serializer:json" json:"payload"`
+	Status        OutboxStatus           `gorm:"type:text;not null;default:'pending';index:idx_outbox_events_due,priority:1" json:"status"`
+	Attempts      int                    `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time              `gorm:"not null;index:idx_outbox_events_due,priority:2" json:"next_attempt_at"`
+	LastError     string                 `gorm:"type:text" json:"last_error,omitempty"`
+	ProcessedAt   *time.Time             `json:"processed_at,omitempty"`
+	CreatedAt     time.Time              `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time              `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (OutboxEvent) TableName() string {
+	return "outbox_events"
+}
+
+// NotificationFanout is the payload of an OutboxKindNotify event. Recipients are resolved when
+// the event is processed: the listed users, the wardens of Block and, with Chiefs, every chief admin.
+type NotificationFanout struct {
+	UserIDs     []uuid.UUID `json:"user_ids,omitempty"`
+	Block       string      `json:"block,omitempty"`
+	Chiefs      bool        `json:"chiefs,omitempty"`
+	Title       string      `json:"title"`
+	Message     string      `json:"message"`
+	Type        string      `json:"type"`
+	RelatedID   *uuid.UUID  `json:"related_id,omitempty"`
+	RelatedType string      `json:"related_type,omitempty"`
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OutpassStatus string
+
+const (
+	OutpassPending    OutpassStatus = "pending"
+	OutpassApproved   OutpassStatus = "approved"
+	OutpassRejected   OutpassStatus = "rejected"
+	OutpassCancelled  OutpassStatus = "cancelled"
+	OutpassCheckedOut OutpassStatus = "checked_out"
+	OutpassReturned   OutpassStatus = "returned"
+)
+
+// outpassTransitions lists the statuses each outpass status may move to. The warden approves
+// or rejects, the gate checks the student out and back in; the student may cancel until departure.
+var outpassTransitions = map[OutpassStatus][]OutpassStatus{
+	OutpassPending:    {OutpassApproved, OutpassRejected, OutpassCancelled},
+	OutpassApproved:   {OutpassCheckedOut, OutpassCancelled},
+	OutpassCheckedOut: {OutpassReturned},
+	OutpassRejected:   {},
+	OutpassCancelled:  {},
+	OutpassReturned:   {},
+}
+
+// CanTransitionTo reports whether an outpass in status s may move to next.
+func (s OutpassStatus) CanTransitionTo(next OutpassStatus) bool {
+	for _, allowed := range outpassTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Outpass is a student's leave request. Late check-ins are flagged and the student is asked
+// to file an outing apology linked back to the outpass.
+type Outpass struct {
+	ID               uuid.UUID     `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	StudentID        uuid.UUID     `gorm:"type:uuid;not null;index" json:"student_id"`
+	Destination      string        `gorm:"type:text;not null" json:"destination"`
+	Reason           string        `gorm:"type:text" json:"reason"`
+	DepartureAt      time.Time     `gorm:"not null" json:"departure_at"`
+	ExpectedReturnAt time.Time     `gorm:"not null" json:"expected_return_at"`
+	Status           OutpassStatus `gorm:"type:text;not null;default:'pending';index" json:"status"`
+
+	ReviewedByID  *uuid.UUID `gorm:"type:uuid" json:"reviewed_by_id,omitempty"`
+	ReviewComment string     `gorm:"type:text" json:"review_comment"`
+	ReviewedAt    *time.Time `json:"reviewed_at,omitempty"`
+
+	// Gate timestamps, recorded by security staff (or a warden) at the hostel gate
+	CheckedOutAt   *time.Time `json:"checked_out_at,omitempty"`
+	CheckedOutByID *uuid.UUID `gorm:"type:uuid" json:"checked_out_by_id,omitempty"`
+	CheckedInAt    *time.Time `json:"checked_in_at,omitempty"`
+	CheckedInByID  *uuid.UUID `gorm:"type:uuid" json:"checked_in_by_id,omitempty"`
+	IsLate         bool       `gorm:"not null;default:false" json:"is_late"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Student StudentModel `gorm:"foreignKey:StudentID;references:UserID" json:"student"`
+	// Apology is the outing apology filed for a late return, if any
+	Apology *Apology `gorm:"foreignKey:OutpassID;constraint:OnDelete:SET NULL;" json:"apology,omitempty"`
+}
+
+func (Outpass) TableName() string {
+	return "outpasses"
+}
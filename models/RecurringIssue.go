@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RecurringIssueStatus string
+
+const (
+	RecurringOpen         RecurringIssueStatus = "open"
+	RecurringAcknowledged RecurringIssueStatus = "acknowledged"
+	RecurringClosed       RecurringIssueStatus = "closed"
+)
+
+// RecurringIssue is raised by the recurring-issue analyzer when a room (and optionally a
+// specific asset) keeps getting complaints of the same type within the detection window.
+type RecurringIssue struct {
+	ID             uuid.UUID            `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Block          string               `gorm:"type:char(1);index" json:"block"`
+	RoomID         uuid.UUID            `gorm:"type:uuid;not null;index" json:"room_id"`
+	AssetID        *uuid.UUID           `gorm:"type:uuid;index" json:"asset_id,omitempty"`
+	Type           ComplaintType        `gorm:"type:text;not null" json:"type"`
+	ComplaintCount int                  `gorm:"not null" json:"complaint_count"`
+	WindowDays     int                  `gorm:"not null" json:"window_days"`
+	FirstSeenAt    time.Time            `json:"first_seen_at"`
+	LastSeenAt     time.Time            `json:"last_seen_at"`
+	Status         RecurringIssueStatus `gorm:"type:text;not null;default:'open';index" json:"status"`
+	Note           string               `gorm:"type:text" json:"note"`
+	CreatedAt      time.Time            `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time            `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Room  *Room  `gorm:"foreignKey:RoomID" json:"room,omitempty"`
+	Asset *Asset `gorm:"foreignKey:AssetID" json:"asset,omitempty"`
+}
+
+func (RecurringIssue) TableName() string {
+	return "recurring_issues"
+}
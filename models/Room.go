@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HostelBlock is a hostel block in the registry. Code is the single letter used as
+// Block on users and student_models.
+type HostelBlock struct {
+	Code      string    `gorm:"type:char(1);primaryKey;check:code ~ '^[A-Z]$'" json:"code"`
+	Name      string    `gorm:"type:text" json:"name"`
+	Floors    int       `gorm:"not null;default:0" json:"floors"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (HostelBlock) TableName() string {
+	return "hostel_blocks"
+}
+
+// Room is a room in a block. Number matches StudentModel.RoomNo.
+type Room struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Block     string    `gorm:"type:char(1);not null;uniqueIndex:idx_rooms_block_number" json:"block"`
+	Number    string    `gorm:"type:text;not null;uniqueIndex:idx_rooms_block_number" json:"number"`
+	Floor     int       `gorm:"not null;default:0" json:"floor"`
+	Capacity  int       `gorm:"not null;default:1" json:"capacity"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	Assets []Asset `gorm:"foreignKey:RoomID;constraint:OnDelete:CASCADE;" json:"assets"`
+}
+
+func (Room) TableName() string {
+	return "rooms"
+}
+
+type AssetStatus string
+
+const (
+	AssetActive  AssetStatus = "active"
+	AssetRetired AssetStatus = "retired"
+)
+
+// Asset is a fixture in a room (geyser, fan, AC, ...) that complaints can point at.
+type Asset struct {
+	ID          uuid.UUID   `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	RoomID      uuid.UUID   `gorm:"type:uuid;not null;index" json:"room_id"`
+	Kind        string      `gorm:"type:text;not null" json:"kind"`
+	Label       string      `gorm:"type:text" json:"label"`
+	SerialNo    string      `gorm:"type:text" json:"serial_no"`
+	Status      AssetStatus `gorm:"type:text;not null;default:'active'" json:"status"`
+	InstalledAt *time.Time  `json:"installed_at,omitempty"`
+	CreatedAt   time.Time   `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (Asset) TableName() string {
+	return "assets"
+}
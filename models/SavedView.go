@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ComplaintFilter is the filter set accepted by the admin complaint list. It is stored as
+// JSON on saved views.
+type ComplaintFilter struct {
+	Status     string   `json:"status,omitempty"`
+	Type       string   `json:"type,omitempty"`
+	Priority   string   `json:"priority,omitempty"`
+	Block      string   `json:"block,omitempty"`
+	AssignedTo string   `json:"assigned_to,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	// From/To bound created_at by calendar date (YYYY-MM-DD, both inclusive)
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+// SavedView is a named filter and sort combination an admin can return to. Shared views
+// are visible to the other admins of the same block.
+type SavedView struct {
+	ID        uuid.UUID       `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	OwnerID   uuid.UUID       `gorm:"type:uuid;not null;index" json:"owner_id"`
+	Block     string          `gorm:"type:char(1);index" json:"block"`
+	Name      string          `gorm:"type:text;not null" json:"name"`
+	Filter    ComplaintFilter `gorm:"type:jsonb;serializer:json" json:"filter"`
+	Sort      string          `gorm:"type:text" json:"sort"`
+	Shared    bool            `gorm:"not null;default:false" json:"shared"`
+	CreatedAt time.Time       `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (SavedView) TableName() string {
+	return "saved_views"
+}
@@ -21,9 +21,21 @@ type StudentModel struct {
 	Hostel string `gorm:"-" json:"hostel"` // Always same as Block, for API alias
 	RoomNo string `gorm:"type:text" json:"room_no"`
 
+	// Guardian contact, used for apology acknowledgements. Not part of MarshalJSON; exposed
+	// through the guardian endpoints only.
+	GuardianName     string `gorm:"type:text" json:"guardian_name"`
+	GuardianRelation string `gorm:"type:text" json:"guardian_relation"`
+	GuardianEmail    string `gorm:"type:text" json:"guardian_email"`
+	GuardianPhone    string `gorm:"type:text" json:"guardian_phone"`
+
 	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE;" json:"user"`
 }
 
+// HasGuardianContact reports whether the guardian can be reached by email or SMS.
+func (s StudentModel) HasGuardianContact() bool {
+	return s.GuardianEmail != "" || s.GuardianPhone != ""
+}
+
 // MarshalJSON to always include hostel as alias for block
 func (s StudentModel) MarshalJSON() ([]byte, error) {
 	type Alias StudentModel
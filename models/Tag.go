@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tag is a free-form label wardens attach to complaints ("needs vendor", "floor 3").
+// Tags are scoped to a block so each warden keeps their own vocabulary.
+type Tag struct {
+	ID        uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Block     string     `gorm:"type:char(1);not null;uniqueIndex:idx_tags_block_name" json:"block"`
+	Name      string     `gorm:"type:text;not null;uniqueIndex:idx_tags_block_name" json:"name"`
+	CreatedBy *uuid.UUID `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (Tag) TableName() string {
+	return "tags"
+}
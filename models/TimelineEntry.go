@@ -6,12 +6,42 @@ import (
 	"github.com/google/uuid"
 )
 
+type TimelineVisibility string
+
+const (
+	// TimelinePublic entries are visible to the student who filed the complaint
+	TimelinePublic TimelineVisibility = "public"
+	// TimelineInternal entries are staff-only notes hidden from students
+	TimelineInternal TimelineVisibility = "internal"
+)
+
+// TimelineEventType classifies a timeline entry so clients can render it and metrics can
+// be computed without parsing Message.
+type TimelineEventType string
+
+const (
+	EventComment         TimelineEventType = "comment"
+	EventStatusChange    TimelineEventType = "status_change"
+	EventAssignment      TimelineEventType = "assignment"
+	EventAttachmentAdded TimelineEventType = "attachment_added"
+	EventPriorityChange  TimelineEventType = "priority_change"
+	EventEscalation      TimelineEventType = "escalation"
+	EventEdited          TimelineEventType = "edited"
+)
+
 type TimelineEntry struct {
 	ID          uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
 	ComplaintID uuid.UUID `gorm:"not null"`
-	Author      string
-	Message     string
-	Timestamp   time.Time
+	// Author holds the role of the author (student, admin, chief_admin); AuthorID identifies the user.
+	Author     string
+	AuthorID   *uuid.UUID         `gorm:"type:uuid;index"`
+	Visibility TimelineVisibility `gorm:"type:text;not null;default:'public'"`
+	EventType  TimelineEventType  `gorm:"type:text;not null;default:'comment';index"`
+	// OldValue/NewValue carry the structured change for non-comment events (e.g. "open" -> "resolved")
+	OldValue  *string `gorm:"type:text"`
+	NewValue  *string `gorm:"type:text"`
+	Message   string
+	Timestamp time.Time
 }
 
 func (TimelineEntry) TableName() string {
@@ -13,6 +13,8 @@ const (
 	Admin      RoleType = "admin"
 	ChiefAdmin RoleType = "chief_admin"
 	Counselor  RoleType = "counselor"
+	// Security staff work the hostel gate of their block and record outpass check-outs/check-ins
+	Security RoleType = "security"
 )
 
 type User struct {
@@ -24,4 +26,7 @@ type User struct {
 	// Block is used to map admin users to a hostel block. For students, block info is in StudentModel.
 	Block     string    `gorm:"type:char(1);not null;check:block ~ '^[A-Z]$'" json:"block"`
 	CreatedAt time.Time `json:"created_at"`
+	// CanRevealConfidential designates the counselors who may reveal the filer of a confidential
+	// complaint; chief admins always can. Granted by the chief admin.
+	CanRevealConfidential bool `gorm:"not null;default:false" json:"can_reveal_confidential"`
 }
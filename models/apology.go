@@ -4,17 +4,57 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type ApologyStatus string
 
 const (
-	ApologySubmitted ApologyStatus = "submitted"
-	ApologyReviewed  ApologyStatus = "reviewed"
-	ApologyAccepted  ApologyStatus = "accepted"
-	ApologyRejected  ApologyStatus = "rejected"
+	// ApologyAwaitingGuardian holds an apology until the guardian acknowledges it (see GuardianAcknowledgement)
+	ApologyAwaitingGuardian ApologyStatus = "awaiting_guardian"
+	ApologySubmitted        ApologyStatus = "submitted"
+	ApologyReviewed         ApologyStatus = "reviewed"
+	ApologyAccepted         ApologyStatus = "accepted"
+	ApologyRejected         ApologyStatus = "rejected"
+	// ApologyWithdrawn: the student took the apology back before review
+	ApologyWithdrawn ApologyStatus = "withdrawn"
 )
 
+// apologyTransitions lists the statuses each apology status may move to. Apologies that need a
+// guardian acknowledgement wait in awaiting_guardian first. A warden then marks the apology
+// reviewed and decides; accepted and rejected are final (see ApologyAppeal). Students may
+// withdraw (and edit) an apology until review starts.
+var apologyTransitions = map[ApologyStatus][]ApologyStatus{
+	ApologyAwaitingGuardian: {ApologySubmitted, ApologyWithdrawn},
+	ApologySubmitted:        {ApologyReviewed, ApologyWithdrawn},
+	ApologyReviewed:         {ApologyAccepted, ApologyRejected},
+	ApologyAccepted:         {},
+	ApologyRejected:         {},
+	ApologyWithdrawn:        {},
+}
+
+// IsValid reports whether s is a known apology status.
+func (s ApologyStatus) IsValid() bool {
+	_, ok := apologyTransitions[s]
+	return ok
+}
+
+// Editable reports whether the student may still edit an apology in status s: only once it is
+// submitted and before review starts, never while the guardian holds the link to it.
+func (s ApologyStatus) Editable() bool {
+	return s == ApologySubmitted
+}
+
+// CanTransitionTo reports whether an apology in status s may move to next.
+func (s ApologyStatus) CanTransitionTo(next ApologyStatus) bool {
+	for _, allowed := range apologyTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
 type ApologyType string
 
 const (
@@ -23,6 +63,15 @@ const (
 	ApologyForOtherReason ApologyType = "miscellaneous"
 )
 
+// IsValid reports whether t is a known apology type.
+func (t ApologyType) IsValid() bool {
+	switch t {
+	case ApologyForOuting, ApologyForMisconduct, ApologyForOtherReason:
+		return true
+	}
+	return false
+}
+
 type Apology struct {
 	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
 	StudentID uuid.UUID `gorm:"type:uuid;not null" json:"student_id"`
@@ -33,11 +82,29 @@ type Apology struct {
 	Description       string        `gorm:"type:text" json:"description"`
 	Status            ApologyStatus `gorm:"type:text;default:'submitted'" json:"status"`
 	Comment           string        `gorm:"type:text" json:"comment"`
-	CreatedAt         time.Time     `gorm:"autoCreateTime" json:"created_at"`
+	// Fields are the structured values required by the type's ApologyTemplate, and Letter the
+	// letter rendered from that template at submission
+	TemplateID *uuid.UUID    `gorm:"type:uuid" json:"template_id,omitempty"`
+	Fields     ApologyFields `gorm:"type:jsonb;serializer:json" json:"fields,omitempty"`
+	Letter     string        `gorm:"type:text" json:"letter,omitempty"`
+	// OutpassID links an outing apology to the outpass whose late return it explains
+	OutpassID *uuid.UUID `gorm:"type:uuid;uniqueIndex" json:"outpass_id,omitempty"`
+	// Version increments on every update and is exposed as the ETag for optimistic locking
+	Version   int       `gorm:"not null;default:1" json:"version"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	// DeletedAt puts the apology in the trash until it is restored or purged
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+	DeletedByID *uuid.UUID     `gorm:"type:uuid" json:"deleted_by_id,omitempty"`
 
 	// Fix relationship: StudentID (apology) -> ID (student_models)
 	Student StudentModel `gorm:"foreignKey:StudentID;references:UserID;constraint:OnDelete:CASCADE;" json:"student"`
 
 	// Attachments uploaded with the apology
 	Attachments []ApologyAttachment `gorm:"foreignKey:ApologyID;constraint:OnDelete:CASCADE;" json:"attachments"`
+
+	// GuardianAck tracks the guardian's acknowledgement when the apology type requires one
+	GuardianAck *GuardianAcknowledgement `gorm:"foreignKey:ApologyID;constraint:OnDelete:CASCADE;" json:"guardian_ack,omitempty"`
+
+	// Appeal is the student's single appeal against a rejection, if any
+	Appeal *ApologyAppeal `gorm:"foreignKey:ApologyID;constraint:OnDelete:CASCADE;" json:"appeal,omitempty"`
 }
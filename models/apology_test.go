@@ -0,0 +1,48 @@
+package models
+
+import "testing"
+
+func TestApologyStatusCanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from, to ApologyStatus
+		want     bool
+	}{
+		{ApologyAwaitingGuardian, ApologySubmitted, true},
+		{ApologyAwaitingGuardian, ApologyWithdrawn, true},
+		{ApologyAwaitingGuardian, ApologyReviewed, false},
+		{ApologySubmitted, ApologyReviewed, true},
+		{ApologySubmitted, ApologyWithdrawn, true},
+		{ApologySubmitted, ApologyAccepted, false},
+		{ApologyReviewed, ApologyAccepted, true},
+		{ApologyReviewed, ApologyRejected, true},
+		{ApologyReviewed, ApologyWithdrawn, false},
+		{ApologyAccepted, ApologyRejected, false},
+		{ApologyRejected, ApologyAccepted, false},
+		{ApologyWithdrawn, ApologySubmitted, false},
+		{"draft", ApologySubmitted, false},
+	}
+	for _, tt := range tests {
+		if got := tt.from.CanTransitionTo(tt.to); got != tt.want {
+			t.Errorf("%q -> %q = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestApologyStatusEditable(t *testing.T) {
+	tests := []struct {
+		status ApologyStatus
+		want   bool
+	}{
+		{ApologySubmitted, true},
+		{ApologyAwaitingGuardian, false},
+		{ApologyReviewed, false},
+		{ApologyAccepted, false},
+		{ApologyRejected, false},
+		{ApologyWithdrawn, false},
+	}
+	for _, tt := range tests {
+		if got := tt.status.Editable(); got != tt.want {
+			t.Errorf("%q.Editable() = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
@@ -12,24 +12,13 @@ func AutoMigrateAll() {
 				CREATE TYPE user_role AS ENUM ('student', 'admin', 'chief_admin', 'counselor'); 
 			END IF;
 
-			-- Complaint types
-			IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'complaint_type') THEN 
-				CREATE TYPE complaint_type AS ENUM (
-					'roommate', 
-					'plumbing', 
-					'cleanliness', 
-					'electricity', 
-					'Lost and Found', 
-					'Other Issues'
-				); 
-			END IF;
-
 			-- Complaint statuses
 			IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'status_type') THEN 
 				CREATE TYPE status_type AS ENUM (
 					'open', 
 					'inprogress', 
-					'resolved'
+					'resolved',
+					'withdrawn'
 				); 
 			END IF;
 			-- Apology types
@@ -54,24 +43,43 @@ func AutoMigrateAll() {
 	`)
 
 	// --- Ensure missing ENUM values exist ---
-	config.DB.Exec(`ALTER TYPE complaint_type ADD VALUE IF NOT EXISTS 'electricity';`)
-	config.DB.Exec(`ALTER TYPE complaint_type ADD VALUE IF NOT EXISTS 'Lost and Found';`)
-	config.DB.Exec(`ALTER TYPE complaint_type ADD VALUE IF NOT EXISTS 'Other Issues';`)
+
+	config.DB.Exec(`ALTER TYPE status_type ADD VALUE IF NOT EXISTS 'withdrawn';`)
 
 	// Ensure user_role enum has chief_admin for existing DBs
 	config.DB.Exec(`ALTER TYPE user_role ADD VALUE IF NOT EXISTS 'chief_admin';`)
+	config.DB.Exec(`ALTER TYPE user_role ADD VALUE IF NOT EXISTS 'security';`)
 
 	// --- Migrate all tables in dependency order ---
 	config.DB.AutoMigrate(
 		&User{},
 		&StudentModel{},
+		&HostelBlock{},
+		&Tag{},
+		&Room{},
+		&Asset{},
 		&Complaint{},
 		&Attachment{},
 		&TimelineEntry{},
+		&ComplaintCategory{},
+		&ComplaintRevision{},
+		&ComplaintFeedback{},
+		&ConfidentialAccessLog{},
+		&RecurringIssue{},
+		&SavedView{},
+		&ComplaintWatcher{},
 		&Apology{}, // ✅ only this line added
 		&ApologyAttachment{},
+		&ApologyAppeal{},
+		&ApologyAppealEvent{},
+		&Outpass{},
+		&ApologyTemplate{},
+		&GuardianAcknowledgement{},
+		&ApologyEvent{},
+		&DisciplinaryStrike{},
 		&PasswordResetToken{},
 		&Notification{},
+		&OutboxEvent{},
 	)
 
 	// --- Explicitly ensure apology_attachments table exists (AutoMigrate can occasionally skip under race or prior partial failures) ---
@@ -91,10 +99,26 @@ func AutoMigrateAll() {
 	// --- Enforce correct column types ---
 	config.DB.Exec(`
 		ALTER TABLE complaints 
-		ALTER COLUMN type TYPE complaint_type USING type::complaint_type,
 		ALTER COLUMN status TYPE status_type USING status::status_type;
 	`)
 
+	// --- Complaint categories replace the complaint_type ENUM ---
+	// Convert the column to text, seed the legacy enum values as categories and link existing rows.
+	config.DB.Exec(`ALTER TABLE complaints ALTER COLUMN type TYPE text USING type::text;`)
+	config.DB.Exec(`INSERT INTO complaint_categories (key, name, default_priority, sla_hours, allows_confidential, is_active, sort_order)
+		VALUES
+			('roommate', 'Roommate', 'medium', 72, true, true, 10),
+			('plumbing', 'Plumbing', 'high', 48, false, true, 20),
+			('cleanliness', 'Cleanliness', 'medium', 48, false, true, 30),
+			('electricity', 'Electricity', 'high', 24, false, true, 40),
+			('Lost and Found', 'Lost and Found', 'low', 0, false, true, 50),
+			('Other Issues', 'Other Issues', 'medium', 0, true, true, 60)
+		ON CONFLICT (key) DO NOTHING;`)
+	config.DB.Exec(`UPDATE complaints SET category_id = complaint_categories.id
+		FROM complaint_categories
+		WHERE complaints.category_id IS NULL AND complaint_categories.key = complaints.type;`)
+	config.DB.Exec(`DROP TYPE IF EXISTS complaint_type;`)
+
 	// --- Add missing columns that may not exist in older DBs ---
 	config.DB.Exec(`DO $$ BEGIN
 		IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='complaints' AND column_name='student_identifier') THEN
@@ -113,6 +137,60 @@ func AutoMigrateAll() {
 		END IF;
 	END $$;`)
 
+	// --- Backfill structured timeline authors from legacy "role:uuid" strings ---
+	config.DB.Exec(`UPDATE timeline_entries
+		SET author_id = split_part(author, ':', 2)::uuid,
+			author = split_part(author, ':', 1)
+		WHERE author_id IS NULL
+			AND author ~ '^[a-z_]+:[0-9a-fA-F-]{36}$';`)
+
+	// --- Backfill timeline event types from legacy free-text messages ---
+	config.DB.Exec(`UPDATE timeline_entries
+		SET event_type = 'status_change', new_value = 'withdrawn'
+		WHERE event_type = 'comment' AND new_value IS NULL
+			AND message LIKE 'Complaint withdrawn by student%';`)
+	config.DB.Exec(`UPDATE timeline_entries
+		SET event_type = 'status_change', new_value = substring(message from '^Status changed to (.*)$')
+		WHERE event_type = 'comment' AND new_value IS NULL
+			AND message LIKE 'Status changed to %';`)
+	config.DB.Exec(`UPDATE timeline_entries t
+		SET old_value = COALESCE(prev.prev_value, 'open')
+		FROM (
+			SELECT id, LAG(new_value) OVER (PARTITION BY complaint_id ORDER BY timestamp) AS prev_value
+			FROM timeline_entries WHERE event_type = 'status_change'
+		) prev
+		WHERE t.id = prev.id AND t.old_value IS NULL;`)
+	config.DB.Exec(`UPDATE timeline_entries
+		SET event_type = 'attachment_added', new_value = substring(message from '^Student added ([0-9]+) attachment')
+		WHERE event_type = 'comment' AND new_value IS NULL
+			AND message LIKE 'Student added % attachment(s)';`)
+	config.DB.Exec(`UPDATE timeline_entries
+		SET event_type = 'edited'
+		WHERE event_type = 'comment' AND message LIKE 'Complaint edited by student%';`)
+
+	// --- Backfill the disciplinary ledger from apologies decided before it existed ---
+	// Severity mirrors StrikeSeverity; strikes expire after the default 180 days.
+	config.DB.Exec(`INSERT INTO disciplinary_strikes (student_id, apology_id, type, outcome, severity, expires_at, created_at, updated_at)
+		SELECT student_id, id, apology_type, status,
+			CASE apology_type
+				WHEN 'outing' THEN CASE status WHEN 'accepted' THEN 1 ELSE 2 END
+				ELSE CASE status WHEN 'accepted' THEN 2 ELSE 3 END
+			END,
+			created_at + INTERVAL '180 days', NOW(), NOW()
+		FROM apologies
+		WHERE apology_type IN ('outing', 'misconduct') AND status IN ('accepted', 'rejected')
+		ON CONFLICT (apology_id) DO NOTHING;`)
+
+	// --- Timeline actors reference users; keep the entry if the user is removed ---
+	config.DB.Exec(`DO $$ BEGIN
+		IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_timeline_entries_author') THEN
+			UPDATE timeline_entries SET author_id = NULL
+				WHERE author_id IS NOT NULL AND author_id NOT IN (SELECT id FROM users);
+			ALTER TABLE timeline_entries ADD CONSTRAINT fk_timeline_entries_author
+				FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE SET NULL;
+		END IF;
+	END $$;`)
+
 	// --- Ensure student_models has student_identifier column and unique index ---
 	config.DB.Exec(`DO $$ BEGIN
 		IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='student_models' AND column_name='student_identifier') THEN
@@ -212,4 +290,25 @@ func AutoMigrateAll() {
 			END IF;
 		END IF;
 	END $$;`)
+
+	// --- Publish notification changes for real-time delivery (see jobs.StartNotificationListener) ---
+	config.DB.Exec(`CREATE OR REPLACE FUNCTION notify_notification_change() RETURNS trigger AS $$
+	DECLARE
+		rec notifications;
+	BEGIN
+		IF TG_OP = 'DELETE' THEN
+			rec := OLD;
+		ELSE
+			rec := NEW;
+		END IF;
+		-- only the read state of an existing notification matters to clients
+		IF TG_OP = 'UPDATE' AND OLD.is_read IS NOT DISTINCT FROM NEW.is_read THEN
+			RETURN NULL;
+		END IF;
+		PERFORM pg_notify('` + NotificationChannel + `', json_build_object('op', TG_OP, 'id', rec.id, 'user_id', rec.user_id)::text);
+		RETURN NULL;
+	END $$ LANGUAGE plpgsql;`)
+	config.DB.Exec(`DROP TRIGGER IF EXISTS notifications_notify ON notifications;`)
+	config.DB.Exec(`CREATE TRIGGER notifications_notify AFTER INSERT OR UPDATE OR DELETE ON notifications
+		FOR EACH ROW EXECUTE FUNCTION notify_notification_change();`)
 }
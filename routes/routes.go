@@ -17,10 +17,15 @@ func SetupRoutes(app *fiber.App) {
 	api.Post("/logout", controllers.Logout)
 	// Cloudinary health (keep public)
 	api.Get("/health/cloudinary", controllers.CloudinaryPing)
+	// Guardian acknowledgement of apologies, authorized by the signed one-time link
+	api.Get("/guardian/acknowledgements/:token", controllers.GetGuardianAcknowledgement)
+	api.Post("/guardian/acknowledgements/:token", controllers.AcknowledgeApology)
 
 	// -------------------------------
 	// PROTECTED ROUTES (JWT required)
 	// -------------------------------
+	// EventSource cannot send headers, so the notification stream also accepts ?access_token=
+	api.Use("/notifications/stream", middlewares.TokenFromQuery)
 	protected := api.Group("/", middlewares.ProtectRoute)
 
 	// -------------------------------
@@ -29,10 +34,29 @@ func SetupRoutes(app *fiber.App) {
 	student := protected.Group("/student", middlewares.RequireRole("student"))
 	student.Post("/complaints", controllers.CreateComplaint)
 	student.Get("/complaints", controllers.GetAllComplaints)
+	student.Put("/complaints/:id", controllers.UpdateOwnComplaint)
+	student.Post("/complaints/:id/attachments", controllers.AddComplaintAttachments)
+	student.Post("/complaints/:id/withdraw", controllers.WithdrawComplaint)
+	student.Post("/complaints/:id/feedback", controllers.SubmitComplaintFeedback)
+
+	student.Get("/room", controllers.GetMyRoom)
+	student.Get("/block-complaints", controllers.GetBlockComplaints)
+	student.Post("/complaints/:id/plus-one", controllers.PlusOneComplaint)
 
 	// ✉️ Student Apologies
 	student.Post("/apologies", controllers.SubmitApology)
 	student.Get("/apologies", controllers.GetStudentApologies)
+	student.Put("/apologies/:id", controllers.UpdateOwnApology) // only before review
+	student.Post("/apologies/:id/withdraw", controllers.WithdrawApology)
+	student.Get("/apologies/:id/history", controllers.GetOwnApologyHistory)
+	student.Post("/apologies/:id/appeal", controllers.AppealApology)
+	student.Get("/apologies/:id/letter", controllers.GetOwnApologyLetter) // PDF letter
+	student.Get("/guardian", controllers.GetMyGuardianContact)
+
+	// 🎫 Outpasses (late returns are apologised for via POST /apologies with outpass_id)
+	student.Post("/outpasses", controllers.CreateOutpass)
+	student.Get("/outpasses", controllers.GetMyOutpasses)
+	student.Post("/outpasses/:id/cancel", controllers.CancelOutpass)
 
 	// -------------------------------
 	// ADMIN / WARDEN ROUTES
@@ -41,14 +65,108 @@ func SetupRoutes(app *fiber.App) {
 
 	// 🧾 Complaints
 	admin.Get("/complaints", controllers.GetAllComplaintsAdmin)
+	admin.Get("/complaints/export", controllers.ExportComplaints) // CSV/XLSX, same filters as the list
+	admin.Get("/complaints/:id", controllers.GetComplaintbyID)
+	admin.Post("/complaints/bulk", controllers.BulkUpdateComplaints)
 	admin.Put("/complaints/:id/status", controllers.UpdateComplaintStatus)
 	admin.Delete("/complaints/:id", controllers.DeleteComplaint)
+	admin.Get("/complaints/:id/revisions", controllers.GetComplaintRevisions)
+	admin.Put("/complaints/:id/assign", controllers.AssignComplaint)
+	admin.Put("/complaints/:id/tags", controllers.UpdateComplaintTags)
+
+	// 🏷️ Tags & saved views
+	admin.Get("/tags", controllers.GetTags)
+	admin.Delete("/tags/:id", controllers.DeleteTag)
+	admin.Get("/views", controllers.GetSavedViews)
+	admin.Post("/views", controllers.CreateSavedView)
+	admin.Put("/views/:id", controllers.UpdateSavedView)
+	admin.Delete("/views/:id", controllers.DeleteSavedView)
+	admin.Get("/metrics/satisfaction", controllers.GetSatisfactionMetrics)
+	admin.Get("/inbox", controllers.GetInbox) // pending complaints/apologies by type, priority and age
 
 	// ✉️ Apologies (admin/warden can see all student apologies)
-	admin.Get("/apologies", controllers.GetApologies)              // View all or filter
-	admin.Get("/apologies/:id", controllers.GetApologyByID)        // View specific apology
-	admin.Put("/apologies/:id/review", controllers.ReviewApology)  // Review/accept/reject apology
-	admin.Get("/apologies/pending", controllers.GetPendingApology) // Count pending apologies
+	admin.Get("/apologies", controllers.GetApologies)                  // View all or filter
+	admin.Get("/apologies/export", controllers.ExportApologies)        // CSV/XLSX, same filters as the list
+	admin.Get("/apologies/pending", controllers.GetPendingApology)     // Count pending apologies (before /:id)
+	admin.Get("/apologies/:id", controllers.GetApologyByID)            // View specific apology
+	admin.Put("/apologies/:id/review", controllers.ReviewApology)      // Review/accept/reject apology
+	admin.Delete("/apologies/:id", controllers.DeleteApology)          // Move apology to trash
+	admin.Get("/apologies/:id/letter", controllers.GetApologyLetter)   // PDF letter
+	admin.Get("/apologies/:id/history", controllers.GetApologyHistory) // includes trashed and purged apologies
+	admin.Post("/apologies/:id/guardian/resend", controllers.ResendGuardianAck)
+	admin.Get("/students/:id/guardian", controllers.GetStudentGuardianContact)
+	admin.Put("/students/:id/guardian", controllers.UpdateStudentGuardianContact)
+
+	// 📝 Apology templates: structured fields and letter layout per type (chief admin manages, everyone can list)
+	protected.Get("/apology-templates", controllers.GetApologyTemplates)
+	apologyTemplates := admin.Group("/apology-templates", middlewares.RequireRole("chief_admin"))
+	apologyTemplates.Put("/:type", controllers.UpsertApologyTemplate)
+	apologyTemplates.Delete("/:type", controllers.DeleteApologyTemplate)
+
+	// 🎫 Outpass approval (warden of the student's block)
+	admin.Get("/outpasses", controllers.GetOutpasses)
+	admin.Put("/outpasses/:id/review", controllers.ReviewOutpass)
+
+	// 📒 Disciplinary ledger (strikes derived from decided apologies)
+	admin.Get("/discipline", controllers.GetDisciplineOverview)
+	admin.Get("/students/:id/discipline", controllers.GetStudentDisciplinaryHistory)
+
+	// ⚖️ Apology appeals (chief admin decides)
+	appeals := admin.Group("/appeals", middlewares.RequireRole("chief_admin"))
+	appeals.Get("/", controllers.GetAppeals)
+	appeals.Put("/:id", controllers.DecideAppeal)
+
+	// 🗑️ Trash: soft-deleted items stay restorable for TRASH_RETENTION_DAYS, then get purged
+	admin.Get("/trash/complaints", controllers.GetTrashedComplaints)
+	admin.Post("/trash/complaints/:id/restore", controllers.RestoreComplaint)
+	admin.Delete("/trash/complaints/:id", middlewares.RequireRole("chief_admin"), controllers.PurgeTrashedComplaint)
+	admin.Get("/trash/apologies", controllers.GetTrashedApologies)
+	admin.Post("/trash/apologies/:id/restore", controllers.RestoreApology)
+	admin.Delete("/trash/apologies/:id", middlewares.RequireRole("chief_admin"), controllers.PurgeTrashedApology)
+
+	// 📤 Notification outbox: dead-lettered events can be inspected and retried
+	outbox := admin.Group("/outbox", middlewares.RequireRole("chief_admin"))
+	outbox.Get("/dead", controllers.GetDeadOutboxEvents)
+	outbox.Post("/:id/retry", controllers.RetryOutboxEvent)
+
+	// 🚪 Hostel gate: security staff (or wardens) record outpass check-outs and check-ins
+	gate := protected.Group("/gate", middlewares.RequireRole("security", "admin", "chief_admin"))
+	gate.Get("/outpasses", controllers.GetGateOutpasses)
+	gate.Post("/outpasses/:id/check-out", controllers.CheckOutOutpass)
+	gate.Post("/outpasses/:id/check-in", controllers.CheckInOutpass)
+
+	// 🕵️ Confidential complaints: identity reveal is audited, and counselors need the chief admin's designation
+	confidential := protected.Group("/confidential", middlewares.RequireRole("chief_admin", "counselor"))
+	confidential.Post("/complaints/:id/reveal", controllers.RevealConfidentialComplaint)
+	admin.Put("/counselors/:id/confidential-reveal", middlewares.RequireRole("chief_admin"), controllers.SetConfidentialRevealer)
+	admin.Get("/complaints/:id/access-log", middlewares.RequireRole("chief_admin"), controllers.GetConfidentialAccessLog)
+
+	// 🗂️ Complaint categories (chief admin manages, everyone can list)
+	protected.Get("/categories", controllers.GetCategories)
+	categories := admin.Group("/categories", middlewares.RequireRole("chief_admin"))
+	categories.Post("/", controllers.CreateCategory)
+	categories.Put("/:id", controllers.UpdateCategory)
+	categories.Delete("/:id", controllers.DeleteCategory)
+
+	// 🏠 Rooms & assets registry
+	admin.Get("/rooms", controllers.GetRooms)
+	admin.Post("/rooms", controllers.CreateRoom)
+	admin.Post("/rooms/import", controllers.ImportRooms)
+	admin.Post("/rooms/:id/assets", controllers.CreateAsset)
+	admin.Get("/assets/failing", controllers.GetFailingAssets)
+	admin.Put("/assets/:id/status", controllers.UpdateAssetStatus)
+	admin.Get("/assets/:id/history", controllers.GetAssetHistory)
+
+	// 📄 PDF reports (block admins get their own block only)
+	admin.Get("/reports/apologies/monthly", controllers.GetApologyReportPDF)
+	admin.Get("/reports/complaints/summary", controllers.GetComplaintSummaryPDF)
+
+	// 🔁 Recurring issues report (chief admin)
+	recurring := admin.Group("/reports/recurring-issues", middlewares.RequireRole("chief_admin"))
+	recurring.Get("/", controllers.GetRecurringIssues)
+	recurring.Post("/scan", controllers.ScanRecurringIssues)
+	recurring.Get("/:id", controllers.GetRecurringIssue)
+	recurring.Put("/:id", controllers.UpdateRecurringIssue)
 
 	// 🔔 Notifications for admins
 	admin.Get("/notifications", controllers.GetNotifications)
@@ -58,6 +176,7 @@ func SetupRoutes(app *fiber.App) {
 
 	// Generic notification endpoints (for students and admins)
 	protected.Get("/notifications", controllers.GetNotifications)
+	protected.Get("/notifications/stream", controllers.StreamNotifications) // SSE: notification, unread_count
 	protected.Patch("/notifications/:id/read", controllers.MarkNotificationRead)
 	protected.Patch("/notifications/read-all", controllers.MarkAllNotificationsRead)
 	protected.Delete("/notifications/:id", controllers.DeleteNotification)
@@ -68,6 +187,7 @@ func SetupRoutes(app *fiber.App) {
 	protected.Get("/metrics/status-summary", controllers.GetStatus)
 	protected.Get("/metrics/resolution-rate", controllers.GetResolutionRate)
 	protected.Get("/metrics/pending-count", controllers.GetPendingComplaint)
+	protected.Get("/metrics/resolution-time", controllers.GetResolutionTime)
 
 	// -------------------------------
 	// Password reset (public)
@@ -81,6 +201,11 @@ func SetupRoutes(app *fiber.App) {
 	protected.Post("/complaints/:id/timeline", controllers.AddTimelineEntry)
 	protected.Get("/complaints/:id/timeline", controllers.GetTimeline)
 
+	// COMPLAINT FOLLOWERS (Shared)
+	protected.Post("/complaints/:id/follow", controllers.FollowComplaint)
+	protected.Delete("/complaints/:id/follow", controllers.UnfollowComplaint)
+	admin.Get("/complaints/:id/followers", controllers.GetComplaintWatchers)
+
 	// (Counselor/counseling routes removed)
 
 	// DEV helper to retrieve latest reset token (only when DEV_MODE=true)